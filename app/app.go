@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"time"
 
 	"github.com/fatih/color"
@@ -18,6 +19,24 @@ var (
 	ErrHeaderNotEqual              = errors.New("header not equal")
 	ErrJsonPathNotFound            = errors.New("json path not found")
 	ErrJsonPathNotEqual            = errors.New("json path not equal")
+	ErrRedirectNotEqual            = errors.New("redirect not equal")
+	ErrDurationExceeded            = errors.New("duration exceeded")
+	ErrSchemaMismatch              = errors.New("response does not match schema")
+	ErrOpenAPIUndocumented         = errors.New("endpoint not documented in openapi spec")
+	ErrOpenAPIMismatch             = errors.New("response does not match openapi spec")
+	ErrSnapshotMismatch            = errors.New("response does not match snapshot")
+	ErrBodyNotEqual                = errors.New("body not equal")
+	ErrSelectorNotFound            = errors.New("selector not found")
+	ErrSelectorNotEqual            = errors.New("selector not equal")
+	ErrTextNotEqual                = errors.New("text not equal")
+	ErrEachNotSatisfied            = errors.New("each not satisfied")
+	ErrAnyNotSatisfied             = errors.New("any not satisfied")
+	ErrAssertionFailed             = errors.New("assertion failed")
+	ErrJWTInvalid                  = errors.New("invalid jwt")
+	ErrJWTSignatureInvalid         = errors.New("invalid jwt signature")
+	ErrJWTClaimNotEqual            = errors.New("jwt claim not equal")
+	ErrUnexpectedExitCode          = errors.New("unexpected exit code")
+	ErrServiceNotReady             = errors.New("service not ready")
 	ErrExtractionPathEmpty         = errors.New("extraction path is empty")
 	ErrExtractionVariableNameEmpty = errors.New("extraction variable name is empty")
 	ErrExtractionPathNotFound      = errors.New("extraction path not found")
@@ -31,54 +50,132 @@ var (
 )
 
 type Config struct {
-	BaseURL     string            `yaml:"base_url"`
-	Headers     map[string]string `yaml:"headers"`
-	Timeout     int               `yaml:"timeout"`
-	StopOnError bool              `yaml:"stop_on_error"`
-	Verbose     bool              `yaml:"verbose"`
+	BaseURL             string            `yaml:"base_url"`
+	Headers             map[string]string `yaml:"headers"`
+	Timeout             int               `yaml:"timeout"`
+	StopOnError         bool              `yaml:"stop_on_error"`
+	Verbose             bool              `yaml:"verbose"`
+	Cookies             bool              `yaml:"cookies"`
+	TLS                 *TLSConfig        `yaml:"tls"`
+	KeepAlive           int               `yaml:"keep_alive"`
+	MaxIdleConns        int               `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost int               `yaml:"max_idle_conns_per_host"`
+	FollowRedirects     *RedirectPolicy   `yaml:"follow_redirects"`
+	Proxy               string            `yaml:"proxy"`
+	Resolve             map[string]string `yaml:"resolve"`
+	OpenAPI             string            `yaml:"openapi"`
+	CoverageReport      string            `yaml:"coverage_report"`
+	UpdateSnapshots     bool              `yaml:"update_snapshots"`
+	JWT                 *JWTConfig        `yaml:"jwt"`
+	Services            []ServiceConfig   `yaml:"services"`
 }
 
 type Global struct {
 	Config Config `yaml:"config"`
+	Hooks  Hooks  `yaml:"hooks"`
 }
 
 type Abdd struct {
 	Global Global `yaml:"global"`
 	Tests  []Test `yaml:"-"`
 
-	Store        map[string]any `yaml:"-"`
-	LastResponse *LastResponse  `yaml:"-"`
-	Client       *http.Client   `yaml:"-"`
+	Store        map[string]any            `yaml:"-"`
+	LastResponse *LastResponse             `yaml:"-"`
+	LastCommand  *CommandResult            `yaml:"-"`
+	Client       *http.Client              `yaml:"-"`
+	Sessions     map[string]http.CookieJar `yaml:"-"`
+	OpenAPI      *OpenAPISpec              `yaml:"-"`
+	Responses    map[string]*LastResponse  `yaml:"-"`
+
+	coverage  []coverageHit
+	services  *serviceGroup
+	interrupt chan struct{}
+	signal    os.Signal
+	fileHooks map[string]*Hooks
 }
 
 type LastResponse struct {
-	Body    *string
-	Code    *int
-	Headers map[string]string
+	Method     string
+	Path       string
+	Body       *string
+	Code       *int
+	Headers    map[string]string
+	RawHeaders http.Header
+	Redirects  []Redirect
+	Timing     Timing
+	Operation  *OpenAPIOperation
+}
+
+// Redirect is a single hop that was followed before the final response.
+type Redirect struct {
+	Method   string
+	URL      string
+	Status   int
+	Location string
 }
 
 type TestRequest struct {
-	Method  string            `yaml:"method"`
-	URL     string            `yaml:"url"`
-	Body    *string           `yaml:"body,omitempty"`
-	Headers map[string]string `yaml:"headers,omitempty"`
+	Method          string            `yaml:"method"`
+	URL             string            `yaml:"url"`
+	Body            *string           `yaml:"body,omitempty"`
+	Headers         map[string]string `yaml:"headers,omitempty"`
+	Session         string            `yaml:"session,omitempty"`
+	ClearSession    bool              `yaml:"clear_session,omitempty"`
+	FollowRedirects *RedirectPolicy   `yaml:"follow_redirects,omitempty"`
 }
 
 type TestCommand struct {
-	Command   string `yaml:"command"`
-	Directory string `yaml:"directory,omitempty"`
-	As        string `yaml:"as,omitempty"`
+	Command   string            `yaml:"command"`
+	Directory string            `yaml:"directory,omitempty"`
+	As        string            `yaml:"as,omitempty"`
+	Env       map[string]string `yaml:"env,omitempty"`
+	Stdin     string            `yaml:"stdin,omitempty"`
+	Timeout   time.Duration     `yaml:"timeout,omitempty"`
+	Shell     string            `yaml:"shell,omitempty"`
+	// ExpectExit lists non-zero exit codes that don't fail the command.
+	ExpectExit []int  `yaml:"expect_exit,omitempty"`
+	StdoutAs   string `yaml:"stdout_as,omitempty"`
+	StderrAs   string `yaml:"stderr_as,omitempty"`
+	ExitCodeAs string `yaml:"exit_code_as,omitempty"`
 }
 
 type TestExpect struct {
-	Headers map[string]string `yaml:"headers,omitempty"`
-	Status  *int              `yaml:"status,omitempty"`
-	Json    map[string]any    `yaml:"json,omitempty"`
+	Headers     map[string]HeaderExpect   `yaml:"headers,omitempty"`
+	Status      *StatusExpect             `yaml:"status,omitempty"`
+	Json        map[string]any            `yaml:"json,omitempty"`
+	Redirects   []ExpectRedirect          `yaml:"redirects,omitempty"`
+	MaxDuration *time.Duration            `yaml:"max_duration,omitempty"`
+	MaxTiming   *TimingLimits             `yaml:"max_timing,omitempty"`
+	Schema      any                       `yaml:"schema,omitempty"`
+	SkipOpenAPI bool                      `yaml:"skip_openapi,omitempty"`
+	Snapshot    *SnapshotExpect           `yaml:"snapshot,omitempty"`
+	Body        *BodyExpect               `yaml:"body,omitempty"`
+	XML         map[string]any            `yaml:"xml,omitempty"`
+	HTML        map[string]any            `yaml:"html,omitempty"`
+	Text        *TextExpect               `yaml:"text,omitempty"`
+	BodySize    *int                      `yaml:"body_size,omitempty"`
+	BodySHA256  string                    `yaml:"body_sha256,omitempty"`
+	Loose       bool                      `yaml:"loose,omitempty"`
+	Each        map[string]map[string]any `yaml:"each,omitempty"`
+	Any         map[string]map[string]any `yaml:"any,omitempty"`
+	Assert      []string                  `yaml:"assert,omitempty"`
+	JWT         map[string]JWTExpect      `yaml:"jwt,omitempty"`
+	ExitCode    *int                      `yaml:"exit_code,omitempty"`
+	Stdout      *TextExpect               `yaml:"stdout,omitempty"`
+	Stderr      *TextExpect               `yaml:"stderr,omitempty"`
+}
+
+type ExpectRedirect struct {
+	Status   *int   `yaml:"status,omitempty"`
+	URL      string `yaml:"url,omitempty"`
+	Location string `yaml:"location,omitempty"`
 }
 
 type TestExtract struct {
-	Path string `yaml:"path"`
-	As   string `yaml:"as"`
+	Path     string `yaml:"path"`
+	As       string `yaml:"as"`
+	Format   string `yaml:"format,omitempty"`
+	JWTClaim string `yaml:"jwt_claim,omitempty"`
 }
 
 type Test struct {
@@ -90,12 +187,24 @@ type Test struct {
 	Command     *TestCommand      `yaml:"command,omitempty"`
 	Expect      TestExpect        `yaml:"expect"`
 	Extract     []TestExtract     `yaml:"extract,omitempty"`
+	// Steps run in order in place of a single request or command.
+	Steps []Test `yaml:"steps,omitempty"`
+	Hooks *Hooks `yaml:"hooks,omitempty"`
+	// SaveResponseAs keeps the response for ${responses.<name>...} references in later tests.
+	SaveResponseAs string `yaml:"save_response_as,omitempty"`
+
+	// File is the test file the test was loaded from.
+	File string `yaml:"-"`
+	// Parent is the test a step belongs to while it runs.
+	Parent *Test `yaml:"-"`
 }
 
 type AbddArgs struct {
-	ConfigFile string
-	Folders    []string
-	Verbose    bool
+	ConfigFile      string
+	Folders         []string
+	Verbose         bool
+	CoverageReport  string
+	UpdateSnapshots bool
 }
 
 func (args *AbddArgs) Validate() error {
@@ -134,8 +243,7 @@ func New(args AbddArgs) (*Abdd, error) {
 
 	// Create a new Abdd instance
 	a := &Abdd{
-		Store:  make(map[string]any),
-		Client: http.DefaultClient,
+		Store: make(map[string]any),
 	}
 
 	// Load the global config from the specified file
@@ -144,14 +252,49 @@ func New(args AbddArgs) (*Abdd, error) {
 		return nil, fmt.Errorf("failed to load global config: %w", err)
 	}
 
-	if a.Global.Config.Timeout != 0 {
-		a.Client.Timeout = time.Duration(a.Global.Config.Timeout) * time.Second
+	if a.Global.Config.OpenAPI != "" {
+		a.OpenAPI, err = LoadOpenAPI(configPath(args.ConfigFile, a.Global.Config.OpenAPI))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load openapi spec: %w", err)
+		}
+	}
+
+	if jwt := a.Global.Config.JWT; jwt != nil {
+		jwt.PublicKeyFile = configPath(args.ConfigFile, jwt.PublicKeyFile)
+	}
+
+	for i := range a.Global.Config.Services {
+		service := &a.Global.Config.Services[i]
+		service.Directory = configPath(args.ConfigFile, service.Directory)
+	}
+
+	if tls := a.Global.Config.TLS; tls != nil {
+		tls.CAFile = configPath(args.ConfigFile, tls.CAFile)
+		tls.CertFile = configPath(args.ConfigFile, tls.CertFile)
+		tls.KeyFile = configPath(args.ConfigFile, tls.KeyFile)
+	}
+
+	a.Client, err = NewClient(a.Global.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
 	}
 
 	if args.Verbose {
 		a.Global.Config.Verbose = true
 	}
 
+	if args.UpdateSnapshots {
+		a.Global.Config.UpdateSnapshots = true
+	}
+
+	if args.CoverageReport != "" {
+		a.Global.Config.CoverageReport = args.CoverageReport
+	}
+
+	if a.Global.Config.CoverageReport != "" && a.OpenAPI == nil {
+		return nil, fmt.Errorf("coverage report %s requires an openapi spec in the config", a.Global.Config.CoverageReport)
+	}
+
 	// Load tests from the specified folders
 	err = a.LoadTests(args.Folders, args.ConfigFile)
 	if err != nil {
@@ -161,6 +304,34 @@ func New(args AbddArgs) (*Abdd, error) {
 	return a, nil
 }
 
+// stepsConflict names a field that cannot be combined with steps, because
+// each step makes its own request and checks its own response.
+func stepsConflict(t *Test) string {
+	switch {
+	case len(t.Steps) == 0:
+		return ""
+	case t.Request != nil:
+		return "request"
+	case t.Command != nil:
+		return "command"
+	case !reflect.ValueOf(t.Expect).IsZero():
+		return "expect"
+	case len(t.Extract) > 0:
+		return "extract"
+	case t.SaveResponseAs != "":
+		return "save_response_as"
+	}
+	return ""
+}
+
+// configPath resolves a path from the config file against the config file's directory.
+func configPath(configFile, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(configFile), path)
+}
+
 func (a *Abdd) LoadGlobal(path string) error {
 	f, err := os.ReadFile(path)
 	if err != nil {
@@ -172,6 +343,13 @@ func (a *Abdd) LoadGlobal(path string) error {
 		return fmt.Errorf("failed to unmarshal config file: %w", err)
 	}
 	a.Global = abdd.Global
+
+	// Global hooks resolve relative paths from the config file.
+	for _, hooks := range [][]Test{a.Global.Hooks.BeforeAll, a.Global.Hooks.AfterAll, a.Global.Hooks.BeforeEach, a.Global.Hooks.AfterEach} {
+		for i := range hooks {
+			hooks[i].File = path
+		}
+	}
 	return nil
 }
 
@@ -204,13 +382,28 @@ func (a *Abdd) LoadTests(folders []string, exclude string) error {
 			return fmt.Errorf("failed to read test file %s: %w", file, err)
 		}
 		var testFile struct {
+			Hooks *Hooks `yaml:"hooks"`
 			Tests []Test `yaml:"tests"`
 		}
 		if err := yaml.Unmarshal(f, &testFile); err != nil {
 			return fmt.Errorf("failed to unmarshal test file %s: %w", file, err)
 		}
 
+		for i := range testFile.Tests {
+			test := &testFile.Tests[i]
+			test.File = file
+			if field := stepsConflict(test); field != "" {
+				return fmt.Errorf("test '%s' in %s cannot have both steps and %s", test.Name, file, field)
+			}
+		}
 		tests = append(tests, testFile.Tests...)
+
+		if testFile.Hooks != nil {
+			if a.fileHooks == nil {
+				a.fileHooks = make(map[string]*Hooks)
+			}
+			a.fileHooks[file] = testFile.Hooks
+		}
 	}
 
 	// Create a map of test names to tests
@@ -273,72 +466,71 @@ func (a *Abdd) LoadTests(folders []string, exclude string) error {
 }
 
 func (a *Abdd) Run() error {
+	// Handle signals before the services start so an interrupt during the
+	// readiness wait still stops them.
+	if len(a.Global.Config.Services) > 0 {
+		defer a.handleSignals()()
+	}
+
+	if err := a.StartServices(); err != nil {
+		return err
+	}
+	defer a.StopServices()
+
+	if err := a.runHooks("before_all", a.Global.Hooks.BeforeAll, ""); err != nil {
+		return errors.Join(err, a.runAfterHooks("after_all", a.Global.Hooks.AfterAll, ""))
+	}
+
 	fmt.Println(headerText("┌─────────────────────────────────┐"))
 	fmt.Println(headerText("               Tests               "))
 
+	fileHooks := a.newFileHookRunner()
 	totalTests := len(a.Tests)
 	passedTests := 0
 	failedTests := 0
 
 	for i, test := range a.Tests {
-		if a.Global.Config.Verbose {
-			a.PrintStartTest(&test)
-		}
-
-		var err error
-		err = a.GenerateFakeData(&test)
-		if err == nil {
-			if a.Global.Config.Verbose {
-				a.PrintGenerateFakeDataStep(&test)
-			}
-			err = a.ReplaceVariables(&test)
-		}
-
-		if err == nil {
-			if a.Global.Config.Verbose {
-				a.PrintReplaceVariablesStep(&test)
-			}
-			err = a.ExecuteCommand(&test)
-		}
-
-		if err == nil {
-			if a.Global.Config.Verbose {
-				a.PrintExecuteCommandStep(&test)
-			}
-			err = a.MakeRequest(&test)
-		}
-
-		if err == nil {
-			if a.Global.Config.Verbose {
-				a.PrintMakeRequestStep(&test)
-			}
-			err = a.ValidateResponse(&test)
+		if a.interrupted() != nil {
+			break
 		}
 
-		if err == nil {
-			if a.Global.Config.Verbose {
-				a.PrintValidateResponseStep(&test)
-			}
-			err = a.ExtractData(&test)
+		if a.Global.Config.Verbose {
+			a.PrintStartTest(&test)
 		}
 
+		var results []StepResult
+		err := fileHooks.before(test.File)
 		if err == nil {
-			if a.Global.Config.Verbose {
-				a.PrintExtractDataStep(&test)
-			}
+			err = a.withEachHooks(&a.Global.Hooks, "", func() error {
+				return a.withEachHooks(a.fileHooks[test.File], test.File, func() error {
+					a.markServiceLogs()
+					var err error
+					results, err = a.RunTest(&test)
+					return err
+				})
+			})
 		}
+		err = errors.Join(err, fileHooks.after(test.File, i))
 
 		if err == nil {
 			passedTests++
 
 			fmt.Printf("[%d/%d] %s %s\n", i+1, totalTests, successText("✓"), test.Name)
+			a.PrintStepResults(results)
 			continue
 		}
 
-		a.PrintFailureDetails(&test)
+		failedTest := &test
+		var stepErr *StepError
+		if errors.As(err, &stepErr) {
+			failedTest = stepErr.Step
+		}
+		a.PrintFailureDetails(failedTest)
+		a.PrintServiceLogs()
 
 		failedTests++
 		fmt.Printf("[%d/%d] %s %s\n", i+1, totalTests, failureText("✗"), test.Name)
+		a.PrintStepResults(results)
 		fmt.Printf("       %s %v\n", failureText("→"), err)
 
 		if a.Global.Config.StopOnError {
@@ -346,6 +538,12 @@ func (a *Abdd) Run() error {
 		}
 	}
 
+	// After hooks run even when the suite stopped early.
+	hookErr := errors.Join(fileHooks.finish(), a.runAfterHooks("after_all", a.Global.Hooks.AfterAll, ""))
+	if hookErr != nil {
+		fmt.Printf("%s %v\n", failureText("✗ after_all hooks failed:"), hookErr)
+	}
+
 	fmt.Println()
 	fmt.Println(headerText("└─────────────────────────────────┘"))
 
@@ -367,6 +565,25 @@ func (a *Abdd) Run() error {
 	fmt.Println()
 	fmt.Println(headerText("└─────────────────────────────────┘"))
 
+	if a.OpenAPI != nil {
+		report := a.Coverage()
+		a.PrintCoverage(report)
+
+		if a.Global.Config.CoverageReport != "" {
+			if err := report.WriteJSON(a.Global.Config.CoverageReport); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := a.interrupted(); err != nil {
+		return errors.Join(err, hookErr)
+	}
+
+	if hookErr != nil {
+		return hookErr
+	}
+
 	if failedTests > 0 && !a.Global.Config.StopOnError {
 		return fmt.Errorf(failureText("%d tests failed"), failedTests)
 	}
@@ -137,6 +137,11 @@ global:
 			args:    app.AbddArgs{ConfigFile: configFile, Folders: []string{testFolder}},
 			wantErr: false,
 		},
+		{
+			name:    "Coverage report without openapi spec",
+			args:    app.AbddArgs{ConfigFile: configFile, Folders: []string{testFolder}, CoverageReport: "coverage.json"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -248,6 +253,7 @@ func TestLoadTests(t *testing.T) {
 			validate: func(t *testing.T, tests []app.Test) {
 				assert.Equal(t, "Test1", tests[0].Name)
 				assert.Equal(t, "GET", tests[0].Request.Method)
+				assert.Equal(t, filepath.Join(tempDir, "tests1", "test1.yaml"), tests[0].File)
 			},
 		},
 		{
@@ -542,7 +548,7 @@ func TestRun(t *testing.T) {
 			As:      "echoCommand",
 		},
 		Expect: app.TestExpect{
-			Status: toPointer(200),
+			Status: app.NewStatusExpect(200),
 		},
 		Extract: []app.TestExtract{
 			{Path: "id", As: "userId"},
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// BodyExpect compares the whole response body with an expected JSON document.
+// Equals is either a JSON string or the document written as YAML. In YAML the
+// document may be written directly, or under equals: with ignore: and
+// ignore_order: next to it.
+type BodyExpect struct {
+	Equals      any      `yaml:"equals"`
+	Ignore      []string `yaml:"ignore,omitempty"`
+	IgnoreOrder bool     `yaml:"ignore_order,omitempty"`
+}
+
+var bodyExpectKeys = []string{"equals", "ignore", "ignore_order"}
+
+func (b *BodyExpect) UnmarshalYAML(unmarshal func(any) error) error {
+	var value any
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+
+	// Without any of the option keys the value is the document itself.
+	options, ok := value.(map[string]any)
+	if !ok || !hasAnyKey(options, bodyExpectKeys) {
+		*b = BodyExpect{Equals: value}
+		return nil
+	}
+
+	for _, key := range sortedKeys(options) {
+		if !containsString(bodyExpectKeys, key) {
+			return fmt.Errorf("unknown key %q in expect.body, expected one of %s", key, strings.Join(bodyExpectKeys, ", "))
+		}
+	}
+	if _, ok := options["equals"]; !ok {
+		return fmt.Errorf("expect.body with ignore or ignore_order needs equals")
+	}
+
+	type plain BodyExpect
+	var expect plain
+	if err := unmarshal(&expect); err != nil {
+		return err
+	}
+	*b = BodyExpect(expect)
+	return nil
+}
+
+func hasAnyKey(m map[string]any, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := m[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *BodyExpect) expected() (any, error) {
+	text, ok := b.Equals.(string)
+	if !ok {
+		return normalizeJSON(b.Equals)
+	}
+
+	var doc any
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		return nil, fmt.Errorf("expected body is not valid json: %w", err)
+	}
+	return doc, nil
+}
+
+// BodyDiff returns the differences between the expected body of the test and
+// the last response, with ignored paths masked on both sides.
+func (a *Abdd) BodyDiff(t *Test) ([]Difference, error) {
+	expected, err := t.Expect.Body.expected()
+	if err != nil {
+		return nil, err
+	}
+
+	var actual any
+	if a.LastResponse.Body == nil || json.Unmarshal([]byte(*a.LastResponse.Body), &actual) != nil {
+		return nil, fmt.Errorf("%w: response body is not valid json", ErrBodyNotEqual)
+	}
+
+	expected = maskPaths(expected, t.Expect.Body.Ignore)
+	actual = maskPaths(actual, t.Expect.Body.Ignore)
+	return DiffJSON(expected, actual, DiffOptions{IgnoreOrder: t.Expect.Body.IgnoreOrder}), nil
+}
+
+func (a *Abdd) validateBody(t *Test) error {
+	diffs, err := a.BodyDiff(t)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(diffs))
+	for i, d := range diffs {
+		messages[i] = d.String()
+	}
+	return fmt.Errorf("%w: %s", ErrBodyNotEqual, strings.Join(messages, "; "))
+}
@@ -0,0 +1,131 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResponseBody(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expect  string
+		body    string
+		wantErr string
+	}{
+		{
+			name: "Equal YAML document",
+			expect: `
+equals:
+  id: 1
+  name: John
+  tags: [a, b]`,
+			body: `{"name": "John", "id": 1.0, "tags": ["a", "b"]}`,
+		},
+		{
+			name: "Unwrapped document",
+			expect: `
+id: 1
+name: John
+tags: [a, b]`,
+			body: `{"name": "John", "id": 1, "tags": ["a", "b"]}`,
+		},
+		{
+			name:    "Unwrapped document mismatch",
+			expect:  `{id: 1, name: x}`,
+			body:    `{"id": 1, "name": "y"}`,
+			wantErr: `body not equal: name: expected "x", got "y"`,
+		},
+		{
+			name:   "Unwrapped JSON string",
+			expect: `'{"id": 1}'`,
+			body:   `{"id": 1}`,
+		},
+		{
+			name:   "Equal JSON string",
+			expect: `equals: '{"id": 1, "name": "John"}'`,
+			body:   `{"id": 1, "name": "John"}`,
+		},
+		{
+			name: "Ignored paths",
+			expect: `
+equals:
+  id: 0
+  items:
+    - id: 0
+      name: first
+ignore: [id, items.*.id]`,
+			body: `{"id": 42, "items": [{"id": 7, "name": "first"}]}`,
+		},
+		{
+			name: "Ignore array order",
+			expect: `
+equals:
+  tags: [a, b]
+ignore_order: true`,
+			body: `{"tags": ["b", "a"]}`,
+		},
+		{
+			name: "Differences",
+			expect: `
+equals:
+  id: 1
+  name: John
+  tags: [a, b]`,
+			body:    `{"id": "1", "tags": ["a", "b", "c"], "email": null}`,
+			wantErr: `body not equal: id: expected 1, got "1"; name: missing, expected "John"; tags.2: unexpected "c"; email: unexpected null`,
+		},
+		{
+			name:    "Response is not json",
+			expect:  `equals: {}`,
+			body:    `OK`,
+			wantErr: "body not equal: response body is not valid json",
+		},
+		{
+			name:    "Expected body is not json",
+			expect:  `equals: '{'`,
+			body:    `{}`,
+			wantErr: "expected body is not valid json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body app.BodyExpect
+			require.NoError(t, yaml.Unmarshal([]byte(tc.expect), &body))
+
+			a := &app.Abdd{LastResponse: &app.LastResponse{Body: toPointer(tc.body)}}
+			err := a.ValidateResponse(&app.Test{Expect: app.TestExpect{Body: &body}})
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestBodyExpectUnmarshalErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "Unknown key", yaml: "equal: {id: 1}\nignore: [id]", wantErr: `unknown key "equal" in expect.body`},
+		{name: "Missing equals", yaml: "ignore_order: true", wantErr: "expect.body with ignore or ignore_order needs equals"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body app.BodyExpect
+			assert.ErrorContains(t, yaml.Unmarshal([]byte(tc.yaml), &body), tc.wantErr)
+		})
+	}
+
+	var expect app.TestExpect
+	require.NoError(t, yaml.Unmarshal([]byte("body: {id: 1, name: x}"), &expect))
+	assert.Equal(t, map[string]any{"id": uint64(1), "name": "x"}, expect.Body.Equals)
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultDialTimeout  = 30 * time.Second
+	defaultKeepAlive    = 30 * time.Second
+	defaultMaxIdleConns = 100
+	defaultMaxRedirects = 10
+)
+
+// RedirectPolicy controls whether redirects are followed. In YAML it is written
+// as true, false or the maximum number of redirects to follow.
+type RedirectPolicy struct {
+	Follow bool
+	Max    int
+}
+
+func (p *RedirectPolicy) UnmarshalYAML(unmarshal func(any) error) error {
+	var value any
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case bool:
+		*p = RedirectPolicy{Follow: v}
+	case uint64:
+		*p = RedirectPolicy{Follow: v > 0, Max: int(v)}
+	case int64:
+		if v < 0 {
+			return fmt.Errorf("follow_redirects cannot be negative")
+		}
+		*p = RedirectPolicy{Follow: v > 0, Max: int(v)}
+	default:
+		return fmt.Errorf("follow_redirects must be true, false or a number, got %v", value)
+	}
+	return nil
+}
+
+// CheckRedirect implements http.Client.CheckRedirect for the policy. When the
+// policy stops following, the redirect response itself is returned.
+func (p RedirectPolicy) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if !p.Follow || (p.Max > 0 && len(via) > p.Max) {
+		return http.ErrUseLastResponse
+	}
+	if len(via) >= defaultMaxRedirects && p.Max == 0 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// NewClient builds a dedicated http.Client from the config so that settings
+// never leak into http.DefaultClient or between Abdd instances.
+func NewClient(c Config) (*http.Client, error) {
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: defaultKeepAlive,
+	}
+	if c.KeepAlive > 0 {
+		dialer.KeepAlive = time.Duration(c.KeepAlive) * time.Second
+	}
+
+	proxy, err := proxyFunc(c.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	dial, err := resolveDialer(dialer, c.Resolve)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dial,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          defaultMaxIdleConns,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		DisableKeepAlives:     c.KeepAlive < 0,
+	}
+	if c.MaxIdleConns > 0 {
+		transport.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure tls: %w", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	client := &http.Client{Transport: transport}
+	if c.Timeout != 0 {
+		client.Timeout = time.Duration(c.Timeout) * time.Second
+	}
+	if c.FollowRedirects != nil {
+		client.CheckRedirect = c.FollowRedirects.CheckRedirect
+	}
+
+	return client, nil
+}
+
+// proxyFunc returns the proxy selector for the configured proxy. An empty value
+// honours HTTP_PROXY, HTTPS_PROXY and NO_PROXY, "direct" disables proxying.
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case "direct":
+		return nil, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %s", proxy)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// resolveDialer wraps the dialer so that connections to the configured
+// host:port pairs are sent to the override address instead, like curl --resolve.
+func resolveDialer(dialer *net.Dialer, overrides map[string]string) (func(context.Context, string, string) (net.Conn, error), error) {
+	if len(overrides) == 0 {
+		return dialer.DialContext, nil
+	}
+
+	addresses := make(map[string]string, len(overrides))
+	for hostPort, address := range overrides {
+		_, port, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resolve entry %s: expected host:port", hostPort)
+		}
+
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, port)
+		}
+		addresses[hostPort] = address
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if address, ok := addresses[addr]; ok {
+			addr = address
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}, nil
+}
@@ -0,0 +1,208 @@
+package app_test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  app.Config
+		expects func(*http.Client)
+	}{
+		{
+			name:   "Defaults",
+			config: app.Config{},
+			expects: func(c *http.Client) {
+				transport := c.Transport.(*http.Transport)
+				assert.Equal(t, time.Duration(0), c.Timeout)
+				assert.Equal(t, 100, transport.MaxIdleConns)
+				assert.False(t, transport.DisableKeepAlives)
+				assert.NotNil(t, transport.Proxy)
+				assert.Nil(t, c.CheckRedirect)
+			},
+		},
+		{
+			name:   "Timeout and connection pool",
+			config: app.Config{Timeout: 5, MaxIdleConns: 10, MaxIdleConnsPerHost: 2},
+			expects: func(c *http.Client) {
+				transport := c.Transport.(*http.Transport)
+				assert.Equal(t, 5*time.Second, c.Timeout)
+				assert.Equal(t, 10, transport.MaxIdleConns)
+				assert.Equal(t, 2, transport.MaxIdleConnsPerHost)
+			},
+		},
+		{
+			name:   "Negative keep alive disables keep-alives",
+			config: app.Config{KeepAlive: -1},
+			expects: func(c *http.Client) {
+				assert.True(t, c.Transport.(*http.Transport).DisableKeepAlives)
+			},
+		},
+		{
+			name:   "TLS settings",
+			config: app.Config{TLS: &app.TLSConfig{ServerName: "api.internal"}},
+			expects: func(c *http.Client) {
+				assert.Equal(t, "api.internal", c.Transport.(*http.Transport).TLSClientConfig.ServerName)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := app.NewClient(tc.config)
+			require.NoError(t, err)
+			assert.NotSame(t, http.DefaultClient, client)
+			tc.expects(client)
+		})
+	}
+
+	_, err := app.NewClient(app.Config{TLS: &app.TLSConfig{MinVersion: "9"}})
+	assert.ErrorContains(t, err, "failed to configure tls")
+}
+
+func TestNewDoesNotMutateDefaultClient(t *testing.T) {
+	tempDir := t.TempDir()
+	testFolder := filepath.Join(tempDir, "tests")
+	require.NoError(t, os.Mkdir(testFolder, 0o755))
+
+	newAbdd := func(timeout int) *app.Abdd {
+		configFile := filepath.Join(tempDir, "config"+strconv.Itoa(timeout)+".yaml")
+		content := "global:\n  config:\n    timeout: " + strconv.Itoa(timeout)
+		require.NoError(t, os.WriteFile(configFile, []byte(content), 0o644))
+
+		a, err := app.New(app.AbddArgs{ConfigFile: configFile, Folders: []string{testFolder}})
+		require.NoError(t, err)
+		return a
+	}
+
+	first := newAbdd(5)
+	second := newAbdd(10)
+
+	assert.Equal(t, time.Duration(0), http.DefaultClient.Timeout)
+	assert.NotSame(t, first.Client, second.Client)
+	assert.Equal(t, 5*time.Second, first.Client.Timeout)
+	assert.Equal(t, 10*time.Second, second.Client.Timeout)
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hops, _ := strconv.Atoi(r.URL.Query().Get("hops"))
+		if hops > 0 {
+			http.Redirect(w, r, "/?hops="+strconv.Itoa(hops-1), http.StatusFound)
+			return
+		}
+		w.Write([]byte("done"))
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name     string
+		yaml     string
+		wantCode int
+	}{
+		{name: "Follow all", yaml: "follow_redirects: true", wantCode: http.StatusOK},
+		{name: "Do not follow", yaml: "follow_redirects: false", wantCode: http.StatusFound},
+		{name: "Follow enough hops", yaml: "follow_redirects: 3", wantCode: http.StatusOK},
+		{name: "Follow too few hops", yaml: "follow_redirects: 2", wantCode: http.StatusFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var config app.Config
+			require.NoError(t, yaml.Unmarshal([]byte(tc.yaml), &config))
+
+			client, err := app.NewClient(config)
+			require.NoError(t, err)
+
+			resp, err := client.Get(server.URL + "/?hops=3")
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.wantCode, resp.StatusCode)
+		})
+	}
+
+	var config app.Config
+	assert.Error(t, yaml.Unmarshal([]byte("follow_redirects: sometimes"), &config))
+}
+
+func TestNewClientProxy(t *testing.T) {
+	var proxiedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURL = r.URL.String()
+		w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+
+	client, err := app.NewClient(app.Config{Proxy: proxy.URL})
+	require.NoError(t, err)
+
+	resp, err := client.Get("http://api.example.test/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "http://api.example.test/users", proxiedURL)
+
+	client, err = app.NewClient(app.Config{Proxy: "direct"})
+	require.NoError(t, err)
+	assert.Nil(t, client.Transport.(*http.Transport).Proxy)
+
+	_, err = app.NewClient(app.Config{Proxy: "not a url"})
+	assert.ErrorContains(t, err, "invalid proxy url")
+}
+
+func TestNewClientResolve(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host))
+	}))
+	defer server.Close()
+
+	serverAddr := server.Listener.Addr().String()
+	host, port, err := net.SplitHostPort(serverAddr)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		resolve map[string]string
+		url     string
+	}{
+		{
+			name:    "Address with port",
+			resolve: map[string]string{"api.example.test:80": serverAddr},
+			url:     "http://api.example.test/",
+		},
+		{
+			name:    "Address without port keeps the original port",
+			resolve: map[string]string{"api.example.test:" + port: host},
+			url:     "http://api.example.test:" + port + "/",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := app.NewClient(app.Config{Proxy: "direct", Resolve: tc.resolve})
+			require.NoError(t, err)
+
+			resp, err := client.Get(tc.url)
+			require.NoError(t, err)
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			assert.Contains(t, string(body), "api.example.test")
+		})
+	}
+
+	_, err = app.NewClient(app.Config{Resolve: map[string]string{"api.example.test": host}})
+	assert.ErrorContains(t, err, "invalid resolve entry")
+}
@@ -2,33 +2,81 @@ package app
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
 )
 
+// CommandResult is the outcome of the last command a test ran.
+type CommandResult struct {
+	Command  string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
 func (a *Abdd) ExecuteCommand(t *Test) error {
 	if t.Command == nil {
 		return nil
 	}
 
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("cmd", "/C", t.Command.Command)
-	} else {
-		cmd = exec.Command("sh", "-c", t.Command.Command)
+	ctx := context.Background()
+	if t.Command.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.Command.Timeout)
+		defer cancel()
 	}
 
+	name, args := shellCommand(t.Command.Shell, t.Command.Command)
+	cmd := exec.CommandContext(ctx, name, args...)
+	// Don't wait forever for children that keep the output pipes open.
+	cmd.WaitDelay = time.Second
+
 	if t.Command.Directory != "" {
 		cmd.Dir = t.Command.Directory
 	}
 
+	if len(t.Command.Env) > 0 {
+		cmd.Env = os.Environ()
+		for _, key := range sortedStringKeys(t.Command.Env) {
+			cmd.Env = append(cmd.Env, key+"="+t.Command.Env[key])
+		}
+	}
+
+	if t.Command.Stdin != "" {
+		cmd.Stdin = strings.NewReader(t.Command.Stdin)
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+
+	result := &CommandResult{Command: t.Command.Command, Stdout: stdout.String(), Stderr: stderr.String()}
+	a.LastCommand = result
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("command execution failed: timed out after %s", t.Command.Timeout)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		// An expected exit code is checked by ValidateCommand instead.
+		if t.Expect.ExitCode != nil || t.Command.allowsExit(result.ExitCode) {
+			err = nil
+		}
+	}
 	if err != nil {
 		if stderr.Len() > 0 {
 			return fmt.Errorf("command execution failed: %s: %w", stderr.String(), err)
@@ -36,8 +84,18 @@ func (a *Abdd) ExecuteCommand(t *Test) error {
 		return fmt.Errorf("command execution failed: %w", err)
 	}
 
-	if t.Command.As != "" {
-		a.Store[t.Command.As] = strings.Trim(stdout.String(), "\n")
+	captures := map[string]any{
+		t.Command.As:         strings.Trim(stdout.String(), "\n"),
+		t.Command.StdoutAs:   strings.Trim(stdout.String(), "\n"),
+		t.Command.StderrAs:   strings.Trim(stderr.String(), "\n"),
+		t.Command.ExitCodeAs: result.ExitCode,
+	}
+	delete(captures, "")
+
+	if len(captures) > 0 {
+		for key, value := range captures {
+			a.Store[key] = value
+		}
 		err = a.ReplaceVariables(t)
 		if err != nil {
 			return fmt.Errorf("failed to replace variables: %w", err)
@@ -45,3 +103,81 @@ func (a *Abdd) ExecuteCommand(t *Test) error {
 	}
 	return nil
 }
+
+// allowsExit reports whether a non-zero exit code is listed in expect_exit.
+func (c *TestCommand) allowsExit(code int) bool {
+	for _, allowed := range c.ExpectExit {
+		if allowed == code {
+			return true
+		}
+	}
+	return false
+}
+
+// shellCommand returns the program and arguments that run command in shell,
+// defaulting to sh on unix and cmd on windows.
+func shellCommand(shell, command string) (string, []string) {
+	if shell == "" {
+		if runtime.GOOS == "windows" {
+			shell = "cmd"
+		} else {
+			shell = "sh"
+		}
+	}
+
+	switch strings.TrimSuffix(strings.ToLower(filepath.Base(shell)), ".exe") {
+	case "cmd":
+		return shell, []string{"/C", command}
+	case "powershell", "pwsh":
+		return shell, []string{"-Command", command}
+	default:
+		return shell, []string{"-c", command}
+	}
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidateCommand checks the exit code and output of the last command. For
+// command-only tests, json expectations are matched against stdout.
+func (a *Abdd) ValidateCommand(t *Test) error {
+	if t.Command == nil {
+		return nil
+	}
+	if a.LastCommand == nil {
+		return fmt.Errorf("no command result to validate")
+	}
+
+	if t.Expect.ExitCode != nil && a.LastCommand.ExitCode != *t.Expect.ExitCode {
+		return fmt.Errorf("%w: expected %d, got %d", ErrUnexpectedExitCode, *t.Expect.ExitCode, a.LastCommand.ExitCode)
+	}
+
+	if t.Expect.Stdout != nil {
+		if err := t.Expect.Stdout.validate("stdout", a.LastCommand.Stdout); err != nil {
+			return err
+		}
+	}
+
+	if t.Expect.Stderr != nil {
+		if err := t.Expect.Stderr.validate("stderr", a.LastCommand.Stderr); err != nil {
+			return err
+		}
+	}
+
+	if t.Request == nil && t.Expect.Json != nil {
+		if !gjson.Valid(a.LastCommand.Stdout) {
+			return fmt.Errorf("%w: stdout is not valid json", ErrJsonPathNotEqual)
+		}
+		if err := validateJSON(a.LastCommand.Stdout, t.Expect.Json, t.Expect.Loose); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,11 +1,14 @@
 package app_test
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
 
 	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExecuteCommand(t *testing.T) {
@@ -93,3 +96,161 @@ func TestExecuteCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandOnlyTests(t *testing.T) {
+	testCases := []struct {
+		name    string
+		test    string
+		wantErr string
+		store   map[string]any
+	}{
+		{
+			name: "Exit code and output",
+			test: `
+command:
+  command: echo seeded; echo warning >&2; exit 3
+expect:
+  exit_code: 3
+  stdout: {equals: "seeded\n", line_count: 1}
+  stderr: {contains: warn}`,
+		},
+		{
+			name: "Unexpected exit code",
+			test: `
+command:
+  command: exit 2
+expect:
+  exit_code: 0`,
+			wantErr: "unexpected exit code: expected 0, got 2",
+		},
+		{
+			name: "Non-zero exit without expectation fails",
+			test: `
+command:
+  command: echo boom >&2; exit 1`,
+			wantErr: "command execution failed: boom",
+		},
+		{
+			name: "Stdout mismatch",
+			test: `
+command:
+  command: echo flushed
+expect:
+  stdout: {matches: '^\d+$'}`,
+			wantErr: `text not equal: expected stdout to match ^\d+$, got "flushed\n"`,
+		},
+		{
+			name: "JSON stdout expectations and extraction",
+			test: `
+command:
+  command: 'echo ''{"user": {"id": 12, "email": "a@example.com"}}'''
+expect:
+  json:
+    user.id: 12
+extract:
+  - {path: user.id, as: userId}
+  - {path: user.email, as: email}`,
+			store: map[string]any{"userId": json.Number("12"), "email": "a@example.com"},
+		},
+		{
+			name: "JSON expectation on non-json stdout",
+			test: `
+command:
+  command: echo ok
+expect:
+  json: {status: ok}`,
+			wantErr: "json path not equal: stdout is not valid json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var test app.Test
+			require.NoError(t, yaml.Unmarshal([]byte(tc.test), &test))
+
+			a := &app.Abdd{Store: map[string]any{}}
+			_, err := a.RunTest(&test)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+			assert.Nil(t, a.LastResponse)
+			for k, v := range tc.store {
+				assert.Equal(t, v, a.Store[k])
+			}
+		})
+	}
+}
+
+func TestExecuteCommandOptions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		command string
+		store   map[string]any
+		wantErr string
+	}{
+		{
+			name: "Templated env and stdin",
+			command: `
+command: 'echo "$GREETING, $(cat)"'
+env: {GREETING: "Hello ${name}"}
+stdin: ${name}
+stdout_as: out`,
+			store: map[string]any{"out": "Hello Ann, Ann"},
+		},
+		{
+			name: "Separate captures",
+			command: `
+command: echo out; echo err >&2; exit 3
+expect_exit: [0, 3]
+stdout_as: out
+stderr_as: err
+exit_code_as: code`,
+			store: map[string]any{"out": "out", "err": "err", "code": 3},
+		},
+		{
+			name: "Exit code not listed",
+			command: `
+command: exit 4
+expect_exit: [0, 3]`,
+			wantErr: "command execution failed: exit status 4",
+		},
+		{
+			name: "Shell override",
+			command: `
+command: echo $0
+shell: bash
+stdout_as: shell`,
+			store: map[string]any{"shell": "bash"},
+		},
+		{
+			name: "Timeout",
+			command: `
+command: sleep 5
+timeout: 100ms`,
+			wantErr: "command execution failed: timed out after 100ms",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var command app.TestCommand
+			require.NoError(t, yaml.Unmarshal([]byte(tc.command), &command))
+
+			a := &app.Abdd{Store: map[string]any{"name": "Ann"}}
+			test := &app.Test{Command: &command}
+			require.NoError(t, a.ReplaceVariables(test))
+
+			err := a.ExecuteCommand(test)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+			for k, v := range tc.store {
+				assert.Equal(t, v, a.Store[k])
+			}
+		})
+	}
+}
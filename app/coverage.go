@@ -0,0 +1,137 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// CoverageReport lists every operation of the OpenAPI spec with the number of
+// requests sent to it during Run, broken down by documented status code.
+type CoverageReport struct {
+	Operations   []OperationCoverage `json:"operations"`
+	Undocumented []RequestCoverage   `json:"undocumented,omitempty"`
+	Covered      int                 `json:"covered"`
+	Total        int                 `json:"total"`
+}
+
+type OperationCoverage struct {
+	Method   string           `json:"method"`
+	Path     string           `json:"path"`
+	Hits     int              `json:"hits"`
+	Statuses []StatusCoverage `json:"statuses"`
+}
+
+type StatusCoverage struct {
+	Status     string `json:"status"`
+	Hits       int    `json:"hits"`
+	Documented bool   `json:"documented"`
+}
+
+type RequestCoverage struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+	Hits   int    `json:"hits"`
+}
+
+type coverageHit struct {
+	operation *OpenAPIOperation
+	method    string
+	path      string
+	status    int
+}
+
+func (a *Abdd) recordCoverage(lr *LastResponse) {
+	a.coverage = append(a.coverage, coverageHit{
+		operation: lr.Operation,
+		method:    lr.Method,
+		path:      lr.Path,
+		status:    *lr.Code,
+	})
+}
+
+// Coverage builds the coverage report from the requests made so far.
+func (a *Abdd) Coverage() *CoverageReport {
+	report := &CoverageReport{}
+	if a.OpenAPI == nil {
+		return report
+	}
+
+	byOperation := map[*OpenAPIOperation]*OperationCoverage{}
+	for _, op := range a.OpenAPI.Operations {
+		oc := OperationCoverage{Method: op.Method, Path: op.Path, Statuses: []StatusCoverage{}}
+		for _, status := range op.Statuses() {
+			oc.Statuses = append(oc.Statuses, StatusCoverage{Status: status, Documented: true})
+		}
+		report.Operations = append(report.Operations, oc)
+	}
+	for i, op := range a.OpenAPI.Operations {
+		byOperation[op] = &report.Operations[i]
+	}
+
+	undocumented := map[RequestCoverage]int{}
+	for _, hit := range a.coverage {
+		oc, ok := byOperation[hit.operation]
+		if !ok {
+			undocumented[RequestCoverage{Method: hit.method, Path: hit.path, Status: hit.status}]++
+			continue
+		}
+
+		oc.Hits++
+		key := documentedStatus(hit.operation, hit.status)
+		found := false
+		for i := range oc.Statuses {
+			if oc.Statuses[i].Status == key {
+				oc.Statuses[i].Hits++
+				found = true
+			}
+		}
+		if !found {
+			oc.Statuses = append(oc.Statuses, StatusCoverage{Status: key, Hits: 1})
+		}
+	}
+
+	for _, hit := range a.coverage {
+		rc := RequestCoverage{Method: hit.method, Path: hit.path, Status: hit.status}
+		if count, ok := undocumented[rc]; ok {
+			rc.Hits = count
+			report.Undocumented = append(report.Undocumented, rc)
+			delete(undocumented, rc)
+		}
+	}
+
+	report.Total = len(report.Operations)
+	for _, oc := range report.Operations {
+		if oc.Hits > 0 {
+			report.Covered++
+		}
+	}
+	return report
+}
+
+// documentedStatus returns the response key of the operation that documents
+// the status code, or the code itself when it is not documented.
+func documentedStatus(op *OpenAPIOperation, status int) string {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if _, ok := op.responses[key]; ok {
+			return key
+		}
+	}
+	return code
+}
+
+// WriteJSON writes the report to the given file.
+func (r *CoverageReport) WriteJSON(path string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal coverage report: %w", err)
+	}
+
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("failed to write coverage report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package app_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverage(t *testing.T) {
+	spec, err := app.LoadOpenAPI(writeOpenAPISpec(t))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	a := &app.Abdd{
+		Global:  app.Global{Config: app.Config{BaseURL: server.URL + "/v1"}},
+		Client:  server.Client(),
+		OpenAPI: spec,
+	}
+
+	for _, r := range []app.TestRequest{
+		{Method: "GET", URL: "/users?status=200"},
+		{Method: "GET", URL: "/users?status=200"},
+		{Method: "GET", URL: "/users?status=500"},
+		{Method: "POST", URL: "/users?status=422"},
+		{Method: "DELETE", URL: "/users/1?status=204"},
+	} {
+		require.NoError(t, a.MakeRequest(&app.Test{Request: &r}))
+	}
+
+	report := a.Coverage()
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 2, report.Covered)
+
+	assert.Equal(t, app.OperationCoverage{
+		Method: "GET",
+		Path:   "/users",
+		Hits:   3,
+		Statuses: []app.StatusCoverage{
+			{Status: "200", Hits: 2, Documented: true},
+			{Status: "500", Hits: 1},
+		},
+	}, report.Operations[0])
+	assert.Equal(t, app.OperationCoverage{
+		Method: "POST",
+		Path:   "/users",
+		Hits:   1,
+		Statuses: []app.StatusCoverage{
+			{Status: "201", Documented: true},
+			{Status: "4XX", Hits: 1, Documented: true},
+		},
+	}, report.Operations[1])
+	assert.Equal(t, 0, report.Operations[2].Hits)
+	assert.Equal(t, []app.RequestCoverage{
+		{Method: "DELETE", Path: "/v1/users/1", Status: 204, Hits: 1},
+	}, report.Undocumented)
+
+	path := filepath.Join(t.TempDir(), "coverage.json")
+	require.NoError(t, report.WriteJSON(path))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var written app.CoverageReport
+	require.NoError(t, json.Unmarshal(b, &written))
+	assert.Equal(t, *report, written)
+}
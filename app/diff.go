@@ -0,0 +1,204 @@
+package app
+
+import (
+	"strconv"
+)
+
+// Difference is a single structural difference between two JSON documents.
+// Path uses gjson syntax; Expected or Actual is missing when the value was
+// added or removed.
+type Difference struct {
+	Path        string
+	Expected    any
+	Actual      any
+	HasExpected bool
+	HasActual   bool
+}
+
+func (d Difference) String() string {
+	path := d.Path
+	if path == "" {
+		path = "@this"
+	}
+
+	switch {
+	case !d.HasActual:
+		return path + ": missing, expected " + jsonString(d.Expected)
+	case !d.HasExpected:
+		return path + ": unexpected " + jsonString(d.Actual)
+	default:
+		return path + ": expected " + jsonString(d.Expected) + ", got " + jsonString(d.Actual)
+	}
+}
+
+type DiffOptions struct {
+	// IgnoreOrder compares arrays as multisets.
+	IgnoreOrder bool
+}
+
+// DiffJSON compares two encoding/json decoded documents and returns every
+// path where they differ, in a stable order.
+func DiffJSON(expected, actual any, opts DiffOptions) []Difference {
+	var diffs []Difference
+	opts.diff("", expected, actual, &diffs)
+	return diffs
+}
+
+func (opts DiffOptions) equal(expected, actual any) bool {
+	var diffs []Difference
+	opts.diff("", expected, actual, &diffs)
+	return len(diffs) == 0
+}
+
+func (opts DiffOptions) diff(path string, expected, actual any, diffs *[]Difference) {
+	switch e := expected.(type) {
+	case map[string]any:
+		a, ok := actual.(map[string]any)
+		if !ok {
+			break
+		}
+
+		for _, key := range sortedKeys(e) {
+			childPath := joinPath(path, escapeGjson(key))
+			if av, ok := a[key]; ok {
+				opts.diff(childPath, e[key], av, diffs)
+			} else {
+				*diffs = append(*diffs, Difference{Path: childPath, Expected: e[key], HasExpected: true})
+			}
+		}
+		for _, key := range sortedKeys(a) {
+			if _, ok := e[key]; !ok {
+				*diffs = append(*diffs, Difference{Path: joinPath(path, escapeGjson(key)), Actual: a[key], HasActual: true})
+			}
+		}
+		return
+	case []any:
+		a, ok := actual.([]any)
+		if !ok {
+			break
+		}
+
+		if opts.IgnoreOrder {
+			opts.diffUnordered(path, e, a, diffs)
+			return
+		}
+
+		for i := 0; i < len(e) || i < len(a); i++ {
+			childPath := joinPath(path, strconv.Itoa(i))
+			switch {
+			case i >= len(a):
+				*diffs = append(*diffs, Difference{Path: childPath, Expected: e[i], HasExpected: true})
+			case i >= len(e):
+				*diffs = append(*diffs, Difference{Path: childPath, Actual: a[i], HasActual: true})
+			default:
+				opts.diff(childPath, e[i], a[i], diffs)
+			}
+		}
+		return
+	}
+
+	if !jsonEqual(expected, actual) {
+		*diffs = append(*diffs, Difference{Path: path, Expected: expected, Actual: actual, HasExpected: true, HasActual: true})
+	}
+}
+
+// diffUnordered pairs every expected item with an equal actual item and
+// reports the items left over on either side.
+func (opts DiffOptions) diffUnordered(path string, expected, actual []any, diffs *[]Difference) {
+	used := make([]bool, len(actual))
+	for i, e := range expected {
+		found := false
+		for j, a := range actual {
+			if !used[j] && opts.equal(e, a) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			*diffs = append(*diffs, Difference{Path: joinPath(path, strconv.Itoa(i)), Expected: e, HasExpected: true})
+		}
+	}
+
+	for j, a := range actual {
+		if !used[j] {
+			*diffs = append(*diffs, Difference{Path: joinPath(path, strconv.Itoa(j)), Actual: a, HasActual: true})
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// escapeGjson escapes characters that have a meaning in gjson paths.
+func escapeGjson(key string) string {
+	escaped := make([]rune, 0, len(key))
+	for _, r := range key {
+		switch r {
+		case '.', '*', '?', '|', '#', '@', '\\':
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, r)
+	}
+	return string(escaped)
+}
+
+const ignoredValue = "<ignored>"
+
+// maskPaths replaces the values at the given paths with a placeholder so
+// they do not take part in comparisons. Paths are dot separated object keys
+// or array indexes, and * matches every key or index.
+func maskPaths(doc any, paths []string) any {
+	for _, path := range paths {
+		doc = maskPath(doc, splitPath(path))
+	}
+	return doc
+}
+
+func maskPath(node any, segments []string) any {
+	if len(segments) == 0 {
+		return ignoredValue
+	}
+
+	segment, rest := segments[0], segments[1:]
+	switch value := node.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if segment == "*" || segment == "#" || segment == key {
+				value[key] = maskPath(child, rest)
+			}
+		}
+	case []any:
+		for i, child := range value {
+			if segment == "*" || segment == "#" || segment == strconv.Itoa(i) {
+				value[i] = maskPath(child, rest)
+			}
+		}
+	}
+	return node
+}
+
+// splitPath splits a dotted path, honouring backslash escaped dots.
+func splitPath(path string) []string {
+	var segments []string
+	var current []rune
+	escaped := false
+	for _, r := range path {
+		switch {
+		case escaped:
+			current = append(current, r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '.':
+			segments = append(segments, string(current))
+			current = current[:0]
+		default:
+			current = append(current, r)
+		}
+	}
+	return append(segments, string(current))
+}
@@ -0,0 +1,76 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffJSON(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		actual   string
+		opts     app.DiffOptions
+		want     []string
+	}{
+		{
+			name:     "Equal documents",
+			expected: `{"a": 1, "b": [1, 2, {"c": null}]}`,
+			actual:   `{"b": [1, 2, {"c": null}], "a": 1.0}`,
+		},
+		{
+			name:     "Changed, missing and unexpected values",
+			expected: `{"a": 1, "b": {"c": "x"}, "d": [1, 2]}`,
+			actual:   `{"a": "1", "b": {}, "d": [1, 2, 3], "e": true}`,
+			want: []string{
+				`a: expected 1, got "1"`,
+				`b.c: missing, expected "x"`,
+				`d.2: unexpected 3`,
+				`e: unexpected true`,
+			},
+		},
+		{
+			name:     "Type change at root",
+			expected: `[1]`,
+			actual:   `{"a": 1}`,
+			want:     []string{`@this: expected [1], got {"a":1}`},
+		},
+		{
+			name:     "Keys with special characters",
+			expected: `{"a.b": 1}`,
+			actual:   `{"a.b": 2}`,
+			want:     []string{`a\.b: expected 1, got 2`},
+		},
+		{
+			name:     "Array order matters by default",
+			expected: `[1, 2]`,
+			actual:   `[2, 1]`,
+			want:     []string{`0: expected 1, got 2`, `1: expected 2, got 1`},
+		},
+		{
+			name:     "Ignore array order",
+			expected: `{"tags": [{"id": 1, "ids": [1, 2]}, {"id": 2}, 3]}`,
+			actual:   `{"tags": [3, {"id": 2}, {"ids": [2, 1], "id": 1}]}`,
+			opts:     app.DiffOptions{IgnoreOrder: true},
+		},
+		{
+			name:     "Ignore array order with leftovers",
+			expected: `[1, 2, 2]`,
+			actual:   `[2, 3, 1]`,
+			opts:     app.DiffOptions{IgnoreOrder: true},
+			want:     []string{`2: missing, expected 2`, `1: unexpected 3`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, d := range app.DiffJSON(decodeJSON(t, tc.expected), decodeJSON(t, tc.actual), tc.opts) {
+				got = append(got, d.String())
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,818 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expressions used by expect.assert are small boolean formulas such as
+//
+//	len(body.items) == body.total && body.items[0].price < 100
+//
+// They support literals (numbers, 'strings', "strings", true, false, null and
+// [lists]), member access with . and [], the arithmetic operators + - * / %,
+// comparisons, && || ! and the functions listed in exprFunctions.
+
+type exprNode interface {
+	eval(e *exprEvaluator) (any, error)
+	source() string
+}
+
+type exprLiteral struct {
+	value any
+	text  string
+}
+
+type exprIdent struct {
+	name string
+}
+
+type exprMember struct {
+	target exprNode
+	index  exprNode
+	text   string
+}
+
+type exprCall struct {
+	name string
+	args []exprNode
+	text string
+}
+
+type exprList struct {
+	items []exprNode
+	text  string
+}
+
+type exprUnary struct {
+	op      string
+	operand exprNode
+	text    string
+}
+
+type exprBinary struct {
+	op          string
+	left, right exprNode
+	text        string
+}
+
+func (n *exprLiteral) source() string { return n.text }
+func (n *exprIdent) source() string   { return n.name }
+func (n *exprMember) source() string  { return n.text }
+func (n *exprCall) source() string    { return n.text }
+func (n *exprList) source() string    { return n.text }
+func (n *exprUnary) source() string   { return n.text }
+func (n *exprBinary) source() string  { return n.text }
+
+// exprValue records the value of a sub-expression for error reporting.
+type exprValue struct {
+	source string
+	value  any
+}
+
+type exprEvaluator struct {
+	env    map[string]any
+	values []exprValue
+}
+
+// evaluateExpression parses and evaluates expr against env. Alongside the result
+// it returns the values of the sub-expressions that were evaluated, in order.
+func evaluateExpression(expr string, env map[string]any) (any, []exprValue, error) {
+	node, err := parseExpression(expr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	normalized, err := normalizeJSON(env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e := &exprEvaluator{env: normalized.(map[string]any)}
+	value, err := node.eval(e)
+	return value, e.values, err
+}
+
+func (e *exprEvaluator) record(n exprNode, value any) {
+	for _, v := range e.values {
+		if v.source == n.source() {
+			return
+		}
+	}
+	e.values = append(e.values, exprValue{source: n.source(), value: value})
+}
+
+func (n *exprLiteral) eval(e *exprEvaluator) (any, error) {
+	return n.value, nil
+}
+
+func (n *exprIdent) eval(e *exprEvaluator) (any, error) {
+	value, ok := e.env[n.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown identifier %s", n.name)
+	}
+	e.record(n, value)
+	return value, nil
+}
+
+func (n *exprMember) eval(e *exprEvaluator) (any, error) {
+	target, err := n.target.eval(e)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	switch t := target.(type) {
+	case nil:
+		value = nil
+	case map[string]any:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: object key must be a string, got %s", n.text, jsonString(index))
+		}
+		value, ok = t[key]
+		if !ok {
+			// Header names and similar keys are matched case-insensitively
+			// when no key matches exactly.
+			var matches []string
+			for _, k := range sortedKeys(t) {
+				if strings.EqualFold(k, key) {
+					matches = append(matches, k)
+				}
+			}
+			if len(matches) > 1 {
+				return nil, fmt.Errorf("%s: key %q is ambiguous, matches %s", n.text, key, strings.Join(matches, ", "))
+			}
+			if len(matches) == 1 {
+				value = t[matches[0]]
+			}
+		}
+	case []any:
+		i, ok := index.(float64)
+		if !ok || i != math.Trunc(i) {
+			return nil, fmt.Errorf("%s: array index must be an integer, got %s", n.text, jsonString(index))
+		}
+		if i < 0 {
+			i += float64(len(t))
+		}
+		if i >= 0 && int(i) < len(t) {
+			value = t[int(i)]
+		}
+	default:
+		return nil, fmt.Errorf("%s: cannot index %s", n.text, exprTypeName(target))
+	}
+
+	e.record(n, value)
+	return value, nil
+}
+
+func (n *exprList) eval(e *exprEvaluator) (any, error) {
+	items := make([]any, len(n.items))
+	for i, item := range n.items {
+		value, err := item.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = value
+	}
+	return items, nil
+}
+
+func (n *exprCall) eval(e *exprEvaluator) (any, error) {
+	fn, ok := exprFunctions[n.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", n.name)
+	}
+
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	value, err := fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.text, err)
+	}
+	e.record(n, value)
+	return value, nil
+}
+
+func (n *exprUnary) eval(e *exprEvaluator) (any, error) {
+	operand, err := n.operand.eval(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	switch n.op {
+	case "!":
+		b, ok := operand.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a boolean, got %s", n.text, exprTypeName(operand))
+		}
+		value = !b
+	case "-":
+		f, ok := operand.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a number, got %s", n.text, exprTypeName(operand))
+		}
+		value = -f
+	}
+
+	e.record(n, value)
+	return value, nil
+}
+
+func (n *exprBinary) eval(e *exprEvaluator) (any, error) {
+	left, err := n.left.eval(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a boolean, got %s", n.left.source(), exprTypeName(left))
+		}
+		if (n.op == "&&" && !l) || (n.op == "||" && l) {
+			return l, nil
+		}
+		right, err := n.right.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a boolean, got %s", n.right.source(), exprTypeName(right))
+		}
+		return r, nil
+	}
+
+	right, err := n.right.eval(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	switch n.op {
+	case "==":
+		value = jsonEqual(left, right)
+	case "!=":
+		value = !jsonEqual(left, right)
+	case "<", "<=", ">", ">=":
+		cmp, err := exprCompare(left, right)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", n.text, err)
+		}
+		switch n.op {
+		case "<":
+			value = cmp < 0
+		case "<=":
+			value = cmp <= 0
+		case ">":
+			value = cmp > 0
+		case ">=":
+			value = cmp >= 0
+		}
+	case "+":
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				value = l + r
+				break
+			}
+		}
+		fallthrough
+	case "-", "*", "/", "%":
+		l, lok := left.(float64)
+		r, rok := right.(float64)
+		if !lok || !rok {
+			return nil, fmt.Errorf("%s: cannot apply %s to %s and %s", n.text, n.op, exprTypeName(left), exprTypeName(right))
+		}
+		switch n.op {
+		case "+":
+			value = l + r
+		case "-":
+			value = l - r
+		case "*":
+			value = l * r
+		case "/":
+			if r == 0 {
+				return nil, fmt.Errorf("%s: division by zero", n.text)
+			}
+			value = l / r
+		case "%":
+			if r == 0 {
+				return nil, fmt.Errorf("%s: division by zero", n.text)
+			}
+			value = math.Mod(l, r)
+		}
+	}
+
+	e.record(n, value)
+	return value, nil
+}
+
+func exprCompare(left, right any) (int, error) {
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return strings.Compare(l, r), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s with %s", exprTypeName(left), exprTypeName(right))
+}
+
+func exprTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return "number"
+	}
+	return jsonType(value)
+}
+
+var exprFunctions = map[string]func(args []any) (any, error){
+	"len": func(args []any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		switch v := args[0].(type) {
+		case string:
+			return float64(len([]rune(v))), nil
+		case []any:
+			return float64(len(v)), nil
+		case map[string]any:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("cannot take the length of %s", exprTypeName(args[0]))
+	},
+	"contains": func(args []any) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+		}
+		switch v := args[0].(type) {
+		case string:
+			s, ok := args[1].(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string to search for, got %s", exprTypeName(args[1]))
+			}
+			return strings.Contains(v, s), nil
+		case []any:
+			for _, item := range v {
+				if jsonEqual(item, args[1]) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]any:
+			key, ok := args[1].(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a key to search for, got %s", exprTypeName(args[1]))
+			}
+			_, found := v[key]
+			return found, nil
+		}
+		return nil, fmt.Errorf("cannot search in %s", exprTypeName(args[0]))
+	},
+	"matches": exprStringFunction(func(s, arg string) (any, error) {
+		matched, err := regexp.MatchString(arg, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", arg, err)
+		}
+		return matched, nil
+	}),
+	"starts_with": exprStringFunction(func(s, arg string) (any, error) {
+		return strings.HasPrefix(s, arg), nil
+	}),
+	"ends_with": exprStringFunction(func(s, arg string) (any, error) {
+		return strings.HasSuffix(s, arg), nil
+	}),
+	"jwt": func(args []any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		token, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a token string, got %s", exprTypeName(args[0]))
+		}
+		return jwtPayload(token)
+	},
+	"lower": func(args []any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %s", exprTypeName(args[0]))
+		}
+		return strings.ToLower(s), nil
+	},
+	"upper": func(args []any) (any, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %s", exprTypeName(args[0]))
+		}
+		return strings.ToUpper(s), nil
+	},
+}
+
+func exprStringFunction(fn func(s, arg string) (any, error)) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %s", exprTypeName(args[0]))
+		}
+		arg, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string argument, got %s", exprTypeName(args[1]))
+		}
+		return fn(s, arg)
+	}
+}
+
+type exprToken struct {
+	kind  string // number, string, ident, op or eof
+	text  string
+	value any
+	start int
+	end   int
+}
+
+func lexExpression(input string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(input)
+	offset := func(i int) int { return len(string(runes[:i])) }
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			f, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s at position %d", text, offset(start))
+			}
+			tokens = append(tokens, exprToken{kind: "number", text: text, value: f, start: offset(start), end: offset(i)})
+		case r == '\'' || r == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", offset(start))
+			}
+			i++
+			tokens = append(tokens, exprToken{kind: "string", text: string(runes[start:i]), value: sb.String(), start: offset(start), end: offset(i)})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: "ident", text: string(runes[start:i]), start: offset(start), end: offset(i)})
+		default:
+			start := i
+			op := string(r)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					op = two
+				}
+			}
+			if !exprOperators[op] {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, offset(start))
+			}
+			i += len([]rune(op))
+			tokens = append(tokens, exprToken{kind: "op", text: op, start: offset(start), end: offset(i)})
+		}
+	}
+
+	return append(tokens, exprToken{kind: "eof", start: len(input), end: len(input)}), nil
+}
+
+var exprOperators = map[string]bool{
+	"==": true, "!=": true, "<=": true, ">=": true, "&&": true, "||": true,
+	"<": true, ">": true, "!": true, "+": true, "-": true, "*": true, "/": true, "%": true,
+	"(": true, ")": true, "[": true, "]": true, ".": true, ",": true,
+}
+
+type exprParser struct {
+	input  string
+	tokens []exprToken
+	pos    int
+}
+
+func parseExpression(input string) (exprNode, error) {
+	tokens, err := lexExpression(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{input: input, tokens: tokens}
+	node, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != "eof" {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok.text, tok.start)
+	}
+	return node, nil
+}
+
+// exprPrecedence lists binary operators from loosest to tightest binding.
+var exprPrecedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != "eof" {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) isOp(ops ...string) bool {
+	tok := p.peek()
+	if tok.kind != "op" {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) (exprToken, error) {
+	if !p.isOp(op) {
+		tok := p.peek()
+		if tok.kind == "eof" {
+			return tok, fmt.Errorf("expected %s at end of expression", op)
+		}
+		return tok, fmt.Errorf("expected %s at position %d, got %s", op, tok.start, tok.text)
+	}
+	return p.next(), nil
+}
+
+// text returns the source between the start of the token at from and the last consumed token.
+func (p *exprParser) text(from int) string {
+	return strings.TrimSpace(p.input[p.tokens[from].start:p.tokens[p.pos-1].end])
+}
+
+func (p *exprParser) parseBinary(level int) (exprNode, error) {
+	if level == len(exprPrecedence) {
+		return p.parseUnary()
+	}
+
+	start := p.pos
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isOp(exprPrecedence[level]...) {
+		op := p.next().text
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &exprBinary{op: op, left: left, right: right, text: p.text(start)}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.isOp("!", "-") {
+		start := p.pos
+		op := p.next().text
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprUnary{op: op, operand: operand, text: p.text(start)}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *exprParser) parsePostfix() (exprNode, error) {
+	start := p.pos
+	node, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.isOp("."):
+			p.next()
+			tok := p.next()
+			if tok.kind != "ident" {
+				return nil, fmt.Errorf("expected a field name at position %d", tok.start)
+			}
+			node = &exprMember{target: node, index: &exprLiteral{value: tok.text, text: tok.text}, text: p.text(start)}
+		case p.isOp("["):
+			p.next()
+			index, err := p.parseBinary(0)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			node = &exprMember{target: node, index: index, text: p.text(start)}
+		case p.isOp("("):
+			ident, ok := node.(*exprIdent)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a function", node.source())
+			}
+			p.next()
+			args, err := p.parseList(")")
+			if err != nil {
+				return nil, err
+			}
+			node = &exprCall{name: ident.name, args: args, text: p.text(start)}
+		default:
+			return node, nil
+		}
+	}
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	start := p.pos
+	tok := p.next()
+	switch tok.kind {
+	case "number", "string":
+		return &exprLiteral{value: tok.value, text: tok.text}, nil
+	case "ident":
+		switch tok.text {
+		case "true":
+			return &exprLiteral{value: true, text: tok.text}, nil
+		case "false":
+			return &exprLiteral{value: false, text: tok.text}, nil
+		case "null":
+			return &exprLiteral{value: nil, text: tok.text}, nil
+		}
+		return &exprIdent{name: tok.text}, nil
+	case "op":
+		switch tok.text {
+		case "(":
+			node, err := p.parseBinary(0)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return node, nil
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &exprList{items: items, text: p.text(start)}, nil
+		}
+	case "eof":
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %s at position %d", tok.text, tok.start)
+}
+
+func (p *exprParser) parseList(closing string) ([]exprNode, error) {
+	var items []exprNode
+	if p.isOp(closing) {
+		p.next()
+		return items, nil
+	}
+
+	for {
+		item, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		if p.isOp(",") {
+			p.next()
+			continue
+		}
+		if _, err := p.expect(closing); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+}
+
+// assertionEnv exposes the last response and the store to assertion expressions.
+func (a *Abdd) assertionEnv() map[string]any {
+	headers := map[string]any{}
+	for k, v := range a.LastResponse.Headers {
+		headers[k] = v
+	}
+
+	var status any
+	if a.LastResponse.Code != nil {
+		status = *a.LastResponse.Code
+	}
+
+	var body any
+	if a.LastResponse.Body != nil {
+		if err := json.Unmarshal([]byte(*a.LastResponse.Body), &body); err != nil {
+			body = *a.LastResponse.Body
+		}
+	}
+
+	store := map[string]any{}
+	for k, v := range a.Store {
+		store[k] = v
+	}
+
+	return map[string]any{
+		"status":    status,
+		"headers":   headers,
+		"body":      body,
+		"duration":  a.LastResponse.Timing.Total.Milliseconds(),
+		"store":     store,
+		"responses": a.responsesEnv(),
+	}
+}
+
+func (a *Abdd) validateAssertions(t *Test) error {
+	if len(t.Expect.Assert) == 0 {
+		return nil
+	}
+
+	env := a.assertionEnv()
+	for _, expr := range t.Expect.Assert {
+		result, values, err := evaluateExpression(expr, env)
+		if err != nil {
+			return fmt.Errorf("invalid assertion %s: %w", expr, err)
+		}
+
+		if passed, ok := result.(bool); !ok {
+			return fmt.Errorf("%w: %s evaluated to %s, not a boolean", ErrAssertionFailed, expr, jsonString(result))
+		} else if !passed {
+			details := make([]string, 0, len(values))
+			for _, v := range values {
+				if v.source != expr {
+					details = append(details, fmt.Sprintf("%s = %s", v.source, jsonString(v.value)))
+				}
+			}
+			if len(details) == 0 {
+				return fmt.Errorf("%w: %s", ErrAssertionFailed, expr)
+			}
+			return fmt.Errorf("%w: %s where %s", ErrAssertionFailed, expr, strings.Join(details, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package app_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateResponseAssertions(t *testing.T) {
+	lastResponse := &app.LastResponse{
+		Code:    toPointer(200),
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    toPointer(`{"total": 2, "items": [{"name": "Widget", "price": 25.5}, {"name": "Gadget", "price": 120}]}`),
+		Timing:  app.Timing{Total: 150 * time.Millisecond},
+	}
+
+	testCases := []struct {
+		name    string
+		assert  []string
+		wantErr string
+	}{
+		{
+			name: "Passing assertions",
+			assert: []string{
+				"status == 200",
+				"len(body.items) == body.total && body.items[0].price < 100",
+				"headers['content-type'] == 'application/json'",
+				"contains(body.items[1].name, 'adg') || false",
+				"duration <= 500 && !(status >= 400)",
+				"body.items[-1].price * 2 - 40 == 200",
+				"store.businessId == body.total && store.name + '!' == 'Acme!'",
+				"contains([1, 2, 3], body.total) && matches(body.items[0].name, '^W')",
+				"body.missing == null && body.missing.nested == null",
+				"store.Id == 'x' && store.ID == 'y' && store.BUSINESSID == 2",
+			},
+		},
+		{
+			name:    "Failure shows sub-expression values",
+			assert:  []string{"len(body.items) == body.total + 1 && body.items[1].price < 100"},
+			wantErr: "assertion failed: len(body.items) == body.total + 1 && body.items[1].price < 100 where body = ",
+		},
+		{
+			name:    "Failure lists evaluated values",
+			assert:  []string{"body.items[1].price < 100"},
+			wantErr: `assertion failed: body.items[1].price < 100 where body = {"items":[{"name":"Widget","price":25.5},{"name":"Gadget","price":120}],"total":2}, body.items = [{"name":"Widget","price":25.5},{"name":"Gadget","price":120}], body.items[1] = {"name":"Gadget","price":120}, body.items[1].price = 120`,
+		},
+		{
+			name:    "Literal false",
+			assert:  []string{"false"},
+			wantErr: "assertion failed: false",
+		},
+		{
+			name:    "Not a boolean",
+			assert:  []string{"body.total"},
+			wantErr: "assertion failed: body.total evaluated to 2, not a boolean",
+		},
+		{
+			name:    "Syntax error",
+			assert:  []string{"status == "},
+			wantErr: "invalid assertion status == : unexpected end of expression",
+		},
+		{
+			name:    "Unknown identifier",
+			assert:  []string{"code == 200"},
+			wantErr: "invalid assertion code == 200: unknown identifier code",
+		},
+		{
+			name:    "Type error",
+			assert:  []string{"body.items < 3"},
+			wantErr: "invalid assertion body.items < 3: body.items < 3: cannot compare array with number",
+		},
+		{
+			name:    "Ambiguous case-insensitive key",
+			assert:  []string{"store.id == 'x'"},
+			wantErr: `invalid assertion store.id == 'x': store.id: key "id" is ambiguous, matches ID, Id`,
+		},
+		{
+			name:    "Unknown function",
+			assert:  []string{"size(body.items) == 2"},
+			wantErr: "unknown function size",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app.Abdd{
+				Store:        map[string]any{"businessId": uint64(2), "name": "Acme", "Id": "x", "ID": "y"},
+				LastResponse: lastResponse,
+			}
+
+			err := a.ValidateResponse(&app.Test{Expect: app.TestExpect{Assert: tc.assert}})
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
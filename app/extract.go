@@ -1,20 +1,32 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/tidwall/gjson"
 )
 
 func (a *Abdd) ExtractData(t *Test) error {
-	if a.LastResponse == nil {
-		return fmt.Errorf("no response to extract data from")
-	}
-
 	if t.Extract == nil {
 		return nil
 	}
 
+	// Command-only tests extract from the command's stdout.
+	var body, contentType string
+	switch {
+	case t.Request == nil && t.Command != nil && a.LastCommand != nil:
+		body = a.LastCommand.Stdout
+	case a.LastResponse == nil:
+		return fmt.Errorf("no response to extract data from")
+	default:
+		if a.LastResponse.Body != nil {
+			body = *a.LastResponse.Body
+		}
+		contentType = a.LastResponse.header("Content-Type")
+	}
+
 	for _, ex := range t.Extract {
 		if ex.Path == "" {
 			return fmt.Errorf("%w: extraction path cannot be empty", ErrExtractionPathNotFound)
@@ -23,28 +35,57 @@ func (a *Abdd) ExtractData(t *Test) error {
 			return fmt.Errorf("%w: extraction variable name cannot be empty", ErrExtractionVariableNameEmpty)
 		}
 
-		value := gjson.Get(*a.LastResponse.Body, ex.Path)
+		format := bodyFormat(ex.Format, contentType)
+		if format != formatJSON {
+			value, ok, err := selectMarkup(body, format, ex.Path)
+			if err != nil {
+				return fmt.Errorf("failed to extract %s: %w", ex.Path, err)
+			}
+			if !ok {
+				return fmt.Errorf("%w: expected %s to be present", ErrExtractionPathNotFound, ex.Path)
+			}
+			a.Store[ex.As] = value
+			continue
+		}
+
+		value := gjson.Get(body, ex.Path)
 		if !value.Exists() {
 			return fmt.Errorf("%w: expected %s to be present", ErrExtractionPathNotFound, ex.Path)
 		}
 
-		switch value.Type {
-		case gjson.String:
-			a.Store[ex.As] = value.String()
-		case gjson.Number:
-			a.Store[ex.As] = value.Float()
-		case gjson.True, gjson.False:
-			a.Store[ex.As] = value.Bool()
-		default:
-			a.Store[ex.As] = value.Raw
+		if ex.JWTClaim != "" {
+			jwt, err := ParseJWT(value.String())
+			if err != nil {
+				return fmt.Errorf("failed to extract %s: %w", ex.Path, err)
+			}
+			value = jwt.Claim(ex.JWTClaim)
+			if !value.Exists() {
+				return fmt.Errorf("%w: expected claim %s to be present in %s", ErrExtractionPathNotFound, ex.JWTClaim, ex.Path)
+			}
 		}
 
-		if value.IsObject() || value.IsArray() || value.IsBool() || value.Type == gjson.Number {
-			a.Store[ex.As] = value.Raw
-		} else {
-			a.Store[ex.As] = value.String()
+		stored, err := storeValue(value)
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", ex.Path, err)
 		}
+		a.Store[ex.As] = stored
 	}
 
 	return nil
 }
+
+// storeValue converts a JSON value for the store. Numbers are kept as
+// json.Number so they keep their exact text.
+func storeValue(value gjson.Result) (any, error) {
+	if value.Type == gjson.String {
+		return value.String(), nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(value.Raw))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
@@ -1,6 +1,7 @@
 package app_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -32,6 +33,22 @@ func TestExtractData(t *testing.T) {
 				"extractedKey": "value",
 			},
 		},
+		{
+			name: "Numbers and booleans keep their type",
+			test: app.Test{
+				Extract: []app.TestExtract{
+					{Path: "id", As: "id"},
+					{Path: "active", As: "active"},
+				},
+			},
+			lastResponse: &app.LastResponse{
+				Body: toPointer(`{"id": 12345678901234567890, "active": false}`),
+			},
+			expectedStore: map[string]any{
+				"id":     json.Number("12345678901234567890"),
+				"active": false,
+			},
+		},
 		{
 			name: "Extraction path does not exist",
 			test: app.Test{
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// HeaderExpect is an assertion on a response header. In YAML it is either the
+// exact expected value or an object of operators.
+type HeaderExpect struct {
+	Equals   string              `yaml:"equals,omitempty"`
+	Contains string              `yaml:"contains,omitempty"`
+	Matches  string              `yaml:"matches,omitempty"`
+	Absent   bool                `yaml:"absent,omitempty"`
+	Values   *HeaderValuesExpect `yaml:"values,omitempty"`
+}
+
+// HeaderValuesExpect asserts on the individual values of a repeated header
+// such as Set-Cookie. Contains and Matches pass when any value satisfies them.
+type HeaderValuesExpect struct {
+	Count    *int   `yaml:"count,omitempty"`
+	Contains string `yaml:"contains,omitempty"`
+	Matches  string `yaml:"matches,omitempty"`
+}
+
+func (h *HeaderExpect) UnmarshalYAML(unmarshal func(any) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		*h = HeaderExpect{Equals: value}
+		return nil
+	}
+
+	type plain HeaderExpect
+	var p plain
+	if err := unmarshal(&p); err != nil {
+		return fmt.Errorf("header expectation must be a string or an object: %w", err)
+	}
+	*h = HeaderExpect(p)
+	return nil
+}
+
+func (h HeaderExpect) String() string {
+	var parts []string
+	if h.Equals != "" {
+		parts = append(parts, h.Equals)
+	}
+	if h.Contains != "" {
+		parts = append(parts, "contains "+h.Contains)
+	}
+	if h.Matches != "" {
+		parts = append(parts, "matches "+h.Matches)
+	}
+	if h.Absent {
+		parts = append(parts, "absent")
+	}
+	if h.Values != nil {
+		if h.Values.Count != nil {
+			parts = append(parts, fmt.Sprintf("%d values", *h.Values.Count))
+		}
+		if h.Values.Contains != "" {
+			parts = append(parts, "a value contains "+h.Values.Contains)
+		}
+		if h.Values.Matches != "" {
+			parts = append(parts, "a value matches "+h.Values.Matches)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// headerValues looks up a response header case-insensitively and returns its
+// individual values.
+func (lr *LastResponse) headerValues(name string) ([]string, bool) {
+	if lr.RawHeaders != nil {
+		values := lr.RawHeaders.Values(name)
+		return values, len(values) > 0
+	}
+
+	for key, value := range lr.Headers {
+		if strings.EqualFold(key, name) {
+			return []string{value}, true
+		}
+	}
+	return nil, false
+}
+
+// header returns the comma-joined values of a response header, looked up case-insensitively.
+func (lr *LastResponse) header(name string) string {
+	values, _ := lr.headerValues(name)
+	return strings.Join(values, ", ")
+}
+
+func (a *Abdd) validateHeader(name string, expected HeaderExpect) error {
+	values, exists := a.LastResponse.headerValues(name)
+	if expected.Absent {
+		if exists {
+			return fmt.Errorf("%w: expected header %s to be absent, got %s", ErrHeaderNotEqual, name, strings.Join(values, ", "))
+		}
+		return nil
+	}
+	if !exists {
+		return fmt.Errorf("%w: expected %s to be present", ErrHeaderNotFound, name)
+	}
+
+	actual := strings.Join(values, ", ")
+	if expected.Equals != "" && actual != expected.Equals {
+		return fmt.Errorf("%w: expected header %s to be %s, got %s", ErrHeaderNotEqual, name, expected.Equals, actual)
+	}
+	if expected.Contains != "" && !strings.Contains(actual, expected.Contains) {
+		return fmt.Errorf("%w: expected header %s to contain %s, got %s", ErrHeaderNotEqual, name, expected.Contains, actual)
+	}
+	if expected.Matches != "" {
+		matched, err := regexp.MatchString(expected.Matches, actual)
+		if err != nil {
+			return fmt.Errorf("invalid header pattern %s: %w", expected.Matches, err)
+		}
+		if !matched {
+			return fmt.Errorf("%w: expected header %s to match %s, got %s", ErrHeaderNotEqual, name, expected.Matches, actual)
+		}
+	}
+
+	if expected.Values != nil {
+		return validateHeaderValues(name, values, expected.Values)
+	}
+	return nil
+}
+
+func validateHeaderValues(name string, values []string, expected *HeaderValuesExpect) error {
+	if expected.Count != nil && len(values) != *expected.Count {
+		return fmt.Errorf("%w: expected header %s to have %d values, got %d", ErrHeaderNotEqual, name, *expected.Count, len(values))
+	}
+
+	if expected.Contains != "" {
+		found := false
+		for _, v := range values {
+			if strings.Contains(v, expected.Contains) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: expected a %s value to contain %s, got %q", ErrHeaderNotEqual, name, expected.Contains, values)
+		}
+	}
+
+	if expected.Matches != "" {
+		r, err := regexp.Compile(expected.Matches)
+		if err != nil {
+			return fmt.Errorf("invalid header pattern %s: %w", expected.Matches, err)
+		}
+
+		found := false
+		for _, v := range values {
+			if r.MatchString(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: expected a %s value to match %s, got %q", ErrHeaderNotEqual, name, expected.Matches, values)
+		}
+	}
+	return nil
+}
+
+func (a *Abdd) replaceVariablesInHeaderExpect(h HeaderExpect) HeaderExpect {
+	h.Equals = a.replaceVariablesInText(h.Equals)
+	h.Contains = a.replaceVariablesInText(h.Contains)
+	h.Matches = a.replaceVariablesInText(h.Matches)
+	if h.Values != nil {
+		values := *h.Values
+		values.Contains = a.replaceVariablesInText(values.Contains)
+		values.Matches = a.replaceVariablesInText(values.Matches)
+		h.Values = &values
+	}
+	return h
+}
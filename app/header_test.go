@@ -0,0 +1,102 @@
+package app_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResponseHeaders(t *testing.T) {
+	response := &app.LastResponse{
+		RawHeaders: http.Header{
+			"Content-Type": []string{"application/json; charset=utf-8"},
+			"Set-Cookie":   []string{"session=abc123; HttpOnly", "theme=dark"},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		expect  string
+		wantErr string
+	}{
+		{
+			name:   "Exact value with lower case name",
+			expect: `content-type: application/json; charset=utf-8`,
+		},
+		{
+			name:    "Exact value mismatch",
+			expect:  `Content-Type: application/json`,
+			wantErr: "header not equal: expected header Content-Type to be application/json, got application/json; charset=utf-8",
+		},
+		{
+			name:   "Contains",
+			expect: `content-type: {contains: application/json}`,
+		},
+		{
+			name:    "Contains mismatch",
+			expect:  `content-type: {contains: text/html}`,
+			wantErr: "header not equal: expected header content-type to contain text/html, got application/json; charset=utf-8",
+		},
+		{
+			name:   "Matches",
+			expect: `Content-Type: {matches: "^application/(.+\\+)?json"}`,
+		},
+		{
+			name:    "Matches mismatch",
+			expect:  `Content-Type: {matches: "^text/"}`,
+			wantErr: "header not equal: expected header Content-Type to match ^text/, got application/json; charset=utf-8",
+		},
+		{
+			name:   "Absent",
+			expect: `X-Debug: {absent: true}`,
+		},
+		{
+			name:    "Absent but present",
+			expect:  `set-cookie: {absent: true}`,
+			wantErr: "header not equal: expected header set-cookie to be absent, got session=abc123; HttpOnly, theme=dark",
+		},
+		{
+			name:    "Missing header",
+			expect:  `X-Request-Id: {contains: abc}`,
+			wantErr: "header not found: expected X-Request-Id to be present",
+		},
+		{
+			name: "Repeated header values",
+			expect: `
+Set-Cookie:
+  values:
+    count: 2
+    contains: session=
+    matches: "^theme=(dark|light)$"`,
+		},
+		{
+			name:    "Repeated header value count",
+			expect:  `Set-Cookie: {values: {count: 1}}`,
+			wantErr: "header not equal: expected header Set-Cookie to have 1 values, got 2",
+		},
+		{
+			name:    "Repeated header value not found",
+			expect:  `Set-Cookie: {values: {matches: "^user="}}`,
+			wantErr: `header not equal: expected a Set-Cookie value to match ^user=, got ["session=abc123; HttpOnly" "theme=dark"]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var headers map[string]app.HeaderExpect
+			require.NoError(t, yaml.Unmarshal([]byte(tc.expect), &headers))
+
+			a := &app.Abdd{LastResponse: response}
+			err := a.ValidateResponse(&app.Test{Expect: app.TestExpect{Headers: headers}})
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
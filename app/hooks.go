@@ -0,0 +1,147 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Hooks are requests or commands run around tests. They can be declared
+// globally, per test file and per test; for a multi-step test the each hooks
+// run around every step.
+type Hooks struct {
+	BeforeAll  []Test `yaml:"before_all,omitempty"`
+	AfterAll   []Test `yaml:"after_all,omitempty"`
+	BeforeEach []Test `yaml:"before_each,omitempty"`
+	AfterEach  []Test `yaml:"after_each,omitempty"`
+}
+
+// runHooks runs hooks in order and stops at the first failure.
+func (a *Abdd) runHooks(kind string, hooks []Test, file string) error {
+	for i := range hooks {
+		if err := a.runHook(kind, i, hooks[i], file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runAfterHooks runs every hook even when some fail. The last response and
+// command are restored afterwards so failure details still describe the test.
+func (a *Abdd) runAfterHooks(kind string, hooks []Test, file string) error {
+	lastResponse, lastCommand := a.LastResponse, a.LastCommand
+	defer func() {
+		a.LastResponse, a.LastCommand = lastResponse, lastCommand
+	}()
+
+	var errs []error
+	for i := range hooks {
+		errs = append(errs, a.runHook(kind, i, hooks[i], file))
+	}
+	return errors.Join(errs...)
+}
+
+// runHook runs a copy of the hook so variable replacement leaves it reusable.
+func (a *Abdd) runHook(kind string, index int, hook Test, file string) error {
+	if hook.Name == "" {
+		hook.Name = fmt.Sprintf("%d", index+1)
+	}
+	if hook.File == "" {
+		hook.File = file
+	}
+
+	if a.Global.Config.Verbose {
+		fmt.Printf("  %s Running %s hook %s\n", infoText("•"), kind, hook.Name)
+	}
+
+	if _, err := a.RunTest(&hook); err != nil {
+		return fmt.Errorf("%s hook %s: %w", kind, hook.Name, err)
+	}
+	return nil
+}
+
+// withEachHooks runs fn between the before_each and after_each hooks of h.
+// The after_each hooks run even when fn or a before_each hook fails.
+func (a *Abdd) withEachHooks(h *Hooks, file string, fn func() error) error {
+	if h == nil {
+		return fn()
+	}
+
+	err := a.runHooks("before_each", h.BeforeEach, file)
+	if err == nil {
+		err = fn()
+	}
+	return errors.Join(err, a.runAfterHooks("after_each", h.AfterEach, file))
+}
+
+// withAllHooks runs fn between the before_all and after_all hooks of h.
+func (a *Abdd) withAllHooks(h *Hooks, file string, fn func() error) error {
+	if h == nil {
+		return fn()
+	}
+
+	err := a.runHooks("before_all", h.BeforeAll, file)
+	if err == nil {
+		err = fn()
+	}
+	return errors.Join(err, a.runAfterHooks("after_all", h.AfterAll, file))
+}
+
+// fileHookRunner tracks which test files have run their before_all hooks so
+// the matching after_all hooks run after the file's last test, or at the end
+// of the run when it stops early.
+type fileHookRunner struct {
+	a       *Abdd
+	last    map[string]int
+	started map[string]error
+}
+
+func (a *Abdd) newFileHookRunner() *fileHookRunner {
+	last := make(map[string]int)
+	for i, test := range a.Tests {
+		last[test.File] = i
+	}
+	return &fileHookRunner{a: a, last: last, started: make(map[string]error)}
+}
+
+// before runs the file's before_all hooks ahead of its first test and returns
+// their error for every test of the file.
+func (r *fileHookRunner) before(file string) error {
+	hooks := r.a.fileHooks[file]
+	if hooks == nil {
+		return nil
+	}
+
+	err, ok := r.started[file]
+	if !ok {
+		err = r.a.runHooks("before_all", hooks.BeforeAll, file)
+		r.started[file] = err
+	}
+	return err
+}
+
+// after runs the file's after_all hooks once test i is its last test.
+func (r *fileHookRunner) after(file string, i int) error {
+	if _, ok := r.started[file]; !ok || r.last[file] != i {
+		return nil
+	}
+
+	delete(r.started, file)
+	return r.a.runAfterHooks("after_all", r.a.fileHooks[file].AfterAll, file)
+}
+
+// finish runs the after_all hooks of files whose tests were cut short.
+func (r *fileHookRunner) finish() error {
+	files := make([]string, 0, len(r.started))
+	for file := range r.started {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var errs []error
+	for _, file := range files {
+		delete(r.started, file)
+		errs = append(errs, r.a.runAfterHooks("after_all", r.a.fileHooks[file].AfterAll, file))
+	}
+	return errors.Join(errs...)
+}
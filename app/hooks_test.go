@@ -0,0 +1,137 @@
+package app_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type requestRecorder struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (r *requestRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	r.requests = append(r.requests, req.Method+" "+req.URL.Path)
+	r.mu.Unlock()
+
+	if req.URL.Path == "/fail" {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	recorder := &requestRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	dir := t.TempDir()
+	testFolder := filepath.Join(dir, "tests")
+	require.NoError(t, os.Mkdir(testFolder, 0o755))
+
+	configFile := filepath.Join(dir, "abdd.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+global:
+  config:
+    base_url: `+server.URL+`
+    stop_on_error: true
+  hooks:
+    before_all:
+      - request: {method: POST, url: /global/before_all}
+    after_all:
+      - request: {method: POST, url: /global/after_all}
+    before_each:
+      - request: {method: POST, url: /global/before_each}
+    after_each:
+      - request: {method: POST, url: /global/after_each}
+`), 0o644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(testFolder, "items.yaml"), []byte(`
+hooks:
+  before_all:
+    - request: {method: POST, url: /file/before_all}
+  after_all:
+    - request: {method: POST, url: /file/after_all}
+  after_each:
+    - request: {method: POST, url: /file/after_each}
+tests:
+  - name: first
+    hooks:
+      before_all:
+        - command: {command: echo seeded, stdout_as: seed}
+      after_all:
+        - request: {method: DELETE, url: "/items/${seed}"}
+    request: {method: GET, url: /ok}
+    expect: {status: 200}
+  - name: second
+    depends: [first]
+    request: {method: GET, url: /fail}
+    expect: {status: 200}
+  - name: third
+    depends: [second]
+    request: {method: GET, url: /ok}
+`), 0o644))
+
+	a, err := app.New(app.AbddArgs{ConfigFile: configFile, Folders: []string{testFolder}})
+	require.NoError(t, err)
+	_ = a.Run()
+
+	assert.Equal(t, []string{
+		"POST /global/before_all",
+		"POST /file/before_all",
+		"POST /global/before_each",
+		"GET /ok",
+		"DELETE /items/seeded",
+		"POST /file/after_each",
+		"POST /global/after_each",
+		"POST /global/before_each",
+		"GET /fail",
+		"POST /file/after_each",
+		"POST /global/after_each",
+		"POST /file/after_all",
+		"POST /global/after_all",
+	}, recorder.requests)
+}
+
+func TestRunTestAfterHookFailure(t *testing.T) {
+	recorder := &requestRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	var test app.Test
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: cleanup fails
+hooks:
+  before_each:
+    - name: prepare
+      request: {method: POST, url: /prepare}
+  after_each:
+    - name: cleanup
+      request: {method: DELETE, url: /fail}
+      expect: {status: 204}
+    - name: audit
+      request: {method: POST, url: /audit}
+request: {method: GET, url: /ok}
+expect: {status: 200}
+`), &test))
+
+	a := &app.Abdd{
+		Global: app.Global{Config: app.Config{BaseURL: server.URL}},
+		Store:  map[string]any{},
+		Client: server.Client(),
+	}
+	_, err := a.RunTest(&test)
+	assert.ErrorContains(t, err, "after_each hook cleanup: unexpected status code: expected 204, got 500")
+	assert.ErrorIs(t, err, app.ErrUnexpectedStatusCode)
+	assert.Equal(t, []string{"POST /prepare", "GET /ok", "DELETE /fail", "POST /audit"}, recorder.requests)
+	assert.Equal(t, "/ok", a.LastResponse.Path)
+}
@@ -0,0 +1,321 @@
+package app
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// JWTConfig holds the keys used to verify token signatures.
+type JWTConfig struct {
+	Secret        string `yaml:"secret,omitempty"`
+	PublicKeyFile string `yaml:"public_key_file,omitempty"`
+}
+
+// JWTExpect asserts on a JSON web token found at a body path.
+type JWTExpect struct {
+	Claims map[string]any `yaml:"claims,omitempty"`
+	Header map[string]any `yaml:"header,omitempty"`
+	Verify bool           `yaml:"verify,omitempty"`
+	Valid  bool           `yaml:"valid,omitempty"`
+}
+
+// JWT is a decoded, unverified JSON web token.
+type JWT struct {
+	Header    string
+	Payload   string
+	Signature []byte
+	signed    string
+}
+
+// ParseJWT decodes the header and payload of a compact serialised token.
+func ParseJWT(token string) (*JWT, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 segments, got %d", ErrJWTInvalid, len(parts))
+	}
+
+	var decoded [3][]byte
+	for i, part := range parts {
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+		if err != nil {
+			return nil, fmt.Errorf("%w: segment %d is not base64url: %v", ErrJWTInvalid, i+1, err)
+		}
+		decoded[i] = b
+	}
+
+	if !gjson.ValidBytes(decoded[0]) || !gjson.ValidBytes(decoded[1]) {
+		return nil, fmt.Errorf("%w: header and payload must be json", ErrJWTInvalid)
+	}
+
+	return &JWT{
+		Header:    string(decoded[0]),
+		Payload:   string(decoded[1]),
+		Signature: decoded[2],
+		signed:    parts[0] + "." + parts[1],
+	}, nil
+}
+
+// Claim returns the payload value at a gjson path.
+func (j *JWT) Claim(path string) gjson.Result {
+	return gjson.Get(j.Payload, path)
+}
+
+// Validate checks the exp and nbf claims against now.
+func (j *JWT) Validate(now time.Time) error {
+	if exp := j.Claim("exp"); exp.Exists() {
+		if expires := time.Unix(exp.Int(), 0); !now.Before(expires) {
+			return fmt.Errorf("%w: token expired at %s", ErrJWTInvalid, expires.UTC().Format(time.RFC3339))
+		}
+	}
+	if nbf := j.Claim("nbf"); nbf.Exists() {
+		if notBefore := time.Unix(nbf.Int(), 0); now.Before(notBefore) {
+			return fmt.Errorf("%w: token not valid before %s", ErrJWTInvalid, notBefore.UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// jwtAlgorithm is how a JWS alg value is verified. keySize is the byte length
+// of each of r and s in an ECDSA signature.
+type jwtAlgorithm struct {
+	family  string
+	hash    crypto.Hash
+	keySize int
+}
+
+var jwtAlgorithms = map[string]jwtAlgorithm{
+	"HS256": {family: "HS", hash: crypto.SHA256},
+	"HS384": {family: "HS", hash: crypto.SHA384},
+	"HS512": {family: "HS", hash: crypto.SHA512},
+	"RS256": {family: "RS", hash: crypto.SHA256},
+	"RS384": {family: "RS", hash: crypto.SHA384},
+	"RS512": {family: "RS", hash: crypto.SHA512},
+	"PS256": {family: "PS", hash: crypto.SHA256},
+	"PS384": {family: "PS", hash: crypto.SHA384},
+	"PS512": {family: "PS", hash: crypto.SHA512},
+	"ES256": {family: "ES", hash: crypto.SHA256, keySize: 32},
+	"ES384": {family: "ES", hash: crypto.SHA384, keySize: 48},
+	"ES512": {family: "ES", hash: crypto.SHA512, keySize: 66},
+	"EdDSA": {family: "EdDSA"},
+}
+
+// Verify checks the signature with the HMAC secret or public key in the config.
+func (j *JWT) Verify(c *JWTConfig) error {
+	alg := gjson.Get(j.Header, "alg").String()
+	if alg == "" || alg == "none" {
+		return fmt.Errorf("%w: unsigned token", ErrJWTSignatureInvalid)
+	}
+	algorithm, ok := jwtAlgorithms[alg]
+	if !ok {
+		return fmt.Errorf("%w: unsupported algorithm %s", ErrJWTSignatureInvalid, alg)
+	}
+	if c == nil {
+		return fmt.Errorf("%w: no jwt secret or public key configured", ErrJWTSignatureInvalid)
+	}
+
+	if algorithm.family == "HS" {
+		if c.Secret == "" {
+			return fmt.Errorf("%w: %s requires a jwt secret", ErrJWTSignatureInvalid, alg)
+		}
+		mac := hmac.New(algorithm.hash.New, []byte(c.Secret))
+		mac.Write([]byte(j.signed))
+		if !hmac.Equal(mac.Sum(nil), j.Signature) {
+			return fmt.Errorf("%w: signature does not match", ErrJWTSignatureInvalid)
+		}
+		return nil
+	}
+
+	key, err := c.publicKey()
+	if err != nil {
+		return err
+	}
+
+	if algorithm.family == "EdDSA" {
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s requires an ed25519 public key", ErrJWTSignatureInvalid, alg)
+		}
+		if !ed25519.Verify(edKey, []byte(j.signed), j.Signature) {
+			return fmt.Errorf("%w: signature does not match", ErrJWTSignatureInvalid)
+		}
+		return nil
+	}
+
+	h := algorithm.hash.New()
+	h.Write([]byte(j.signed))
+	digest := h.Sum(nil)
+
+	switch algorithm.family {
+	case "RS", "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s requires an rsa public key", ErrJWTSignatureInvalid, alg)
+		}
+		if algorithm.family == "RS" {
+			err = rsa.VerifyPKCS1v15(rsaKey, algorithm.hash, digest, j.Signature)
+		} else {
+			err = rsa.VerifyPSS(rsaKey, algorithm.hash, digest, j.Signature, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: signature does not match", ErrJWTSignatureInvalid)
+		}
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s requires an ecdsa public key", ErrJWTSignatureInvalid, alg)
+		}
+		// ECDSA signatures are the fixed-size concatenation of r and s.
+		size := algorithm.keySize
+		if len(j.Signature) != 2*size {
+			return fmt.Errorf("%w: %s signature must be %d bytes, got %d", ErrJWTSignatureInvalid, alg, 2*size, len(j.Signature))
+		}
+		r := new(big.Int).SetBytes(j.Signature[:size])
+		s := new(big.Int).SetBytes(j.Signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("%w: signature does not match", ErrJWTSignatureInvalid)
+		}
+	}
+	return nil
+}
+
+func (c *JWTConfig) publicKey() (crypto.PublicKey, error) {
+	if c.PublicKeyFile == "" {
+		return nil, fmt.Errorf("%w: no jwt public key configured", ErrJWTSignatureInvalid)
+	}
+
+	b, err := os.ReadFile(c.PublicKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwt public key: %w", err)
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no pem data found in jwt public key %s", c.PublicKeyFile)
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jwt certificate: %w", err)
+		}
+		return cert.PublicKey, nil
+	default:
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+}
+
+// validateJWT checks every token assertion in expect.jwt.
+func (a *Abdd) validateJWT(t *Test) error {
+	if t.Expect.JWT == nil || a.LastResponse.Body == nil {
+		return nil
+	}
+
+	paths := make([]string, 0, len(t.Expect.JWT))
+	for path := range t.Expect.JWT {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		expect := t.Expect.JWT[path]
+
+		token := gjson.Get(*a.LastResponse.Body, path)
+		if !token.Exists() {
+			return fmt.Errorf("%w: expected %s to be present", ErrJsonPathNotFound, path)
+		}
+
+		jwt, err := ParseJWT(token.String())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		if expect.Verify {
+			if err := jwt.Verify(a.Global.Config.JWT); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+
+		if expect.Valid {
+			if err := jwt.Validate(time.Now()); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+
+		for _, key := range sortedKeys(expect.Header) {
+			if err := compareJWTValue(path, "header "+key, jwt.Header, key, expect.Header[key]); err != nil {
+				return err
+			}
+		}
+
+		for _, key := range sortedKeys(expect.Claims) {
+			if err := compareJWTValue(path, "claim "+key, jwt.Payload, key, expect.Claims[key]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func compareJWTValue(path, name, document, key string, expected any) error {
+	actual := gjson.Get(document, key)
+	if !actual.Exists() {
+		return fmt.Errorf("%w: %s: expected %s to be present", ErrJWTClaimNotEqual, path, name)
+	}
+	if mismatch := jsonMismatch(name, expected, actual, false); mismatch != "" {
+		return fmt.Errorf("%w: %s: %s", ErrJWTClaimNotEqual, path, mismatch)
+	}
+	return nil
+}
+
+func (a *Abdd) replaceVariablesInJWTExpect(expectations map[string]JWTExpect) map[string]JWTExpect {
+	if expectations == nil {
+		return nil
+	}
+
+	replaced := make(map[string]JWTExpect, len(expectations))
+	for path, expect := range expectations {
+		if expect.Claims != nil {
+			expect.Claims = a.replaceVariablesInValue(expect.Claims).(map[string]any)
+		}
+		if expect.Header != nil {
+			expect.Header = a.replaceVariablesInValue(expect.Header).(map[string]any)
+		}
+		replaced[path] = expect
+	}
+	return replaced
+}
+
+// jwtPayload decodes the claims of a token for use in assertion expressions.
+func jwtPayload(token string) (any, error) {
+	jwt, err := ParseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload any
+	if err := json.Unmarshal([]byte(jwt.Payload), &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWTInvalid, err)
+	}
+	return payload, nil
+}
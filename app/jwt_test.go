@@ -0,0 +1,214 @@
+package app_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signJWT(t *testing.T, alg string, claims map[string]any, sign func(signed []byte) []byte) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]any{"alg": alg, "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func hmacJWT(t *testing.T, secret string, claims map[string]any) string {
+	return signJWT(t, "HS256", claims, func(signed []byte) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(signed)
+		return mac.Sum(nil)
+	})
+}
+
+func writePublicKey(t *testing.T, key any) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "public.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644))
+	return path
+}
+
+func TestValidateResponseJWT(t *testing.T) {
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Hour).Unix()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	claims := map[string]any{"sub": "42", "exp": future, "roles": []string{"admin", "user"}}
+	rsaToken := signJWT(t, "RS256", claims, func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return sig
+	})
+	ecToken := signJWT(t, "ES256", claims, func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		require.NoError(t, err)
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig
+	})
+
+	testCases := []struct {
+		name    string
+		token   string
+		config  *app.JWTConfig
+		expect  string
+		wantErr string
+	}{
+		{
+			name:   "HMAC signature and claims",
+			token:  hmacJWT(t, "s3cret", claims),
+			config: &app.JWTConfig{Secret: "s3cret"},
+			expect: `
+verify: true
+valid: true
+header: {alg: HS256}
+claims:
+  sub: "42"
+  roles: [admin, user]
+  roles.#: 2`,
+		},
+		{
+			name:   "RSA signature",
+			token:  rsaToken,
+			config: &app.JWTConfig{PublicKeyFile: writePublicKey(t, &rsaKey.PublicKey)},
+			expect: `verify: true`,
+		},
+		{
+			name:   "ECDSA signature",
+			token:  ecToken,
+			config: &app.JWTConfig{PublicKeyFile: writePublicKey(t, &ecKey.PublicKey)},
+			expect: `verify: true`,
+		},
+		{
+			name:    "Unknown algorithm",
+			token:   signJWT(t, "SHA256", claims, func([]byte) []byte { return []byte("sig") }),
+			config:  &app.JWTConfig{Secret: "s3cret"},
+			expect:  `verify: true`,
+			wantErr: "invalid jwt signature: unsupported algorithm SHA256",
+		},
+		{
+			name:    "ECDSA signature of the wrong length",
+			token:   signJWT(t, "ES256", claims, func([]byte) []byte { return make([]byte, 96) }),
+			config:  &app.JWTConfig{PublicKeyFile: writePublicKey(t, &ecKey.PublicKey)},
+			expect:  `verify: true`,
+			wantErr: "invalid jwt signature: ES256 signature must be 64 bytes, got 96",
+		},
+		{
+			name:    "Wrong HMAC secret",
+			token:   hmacJWT(t, "other", claims),
+			config:  &app.JWTConfig{Secret: "s3cret"},
+			expect:  `verify: true`,
+			wantErr: "access_token: invalid jwt signature: signature does not match",
+		},
+		{
+			name:    "Wrong public key",
+			token:   rsaToken,
+			config:  &app.JWTConfig{PublicKeyFile: writePublicKey(t, &ecKey.PublicKey)},
+			expect:  `verify: true`,
+			wantErr: "invalid jwt signature: RS256 requires an rsa public key",
+		},
+		{
+			name:    "No key configured",
+			token:   rsaToken,
+			expect:  `verify: true`,
+			wantErr: "invalid jwt signature: no jwt secret or public key configured",
+		},
+		{
+			name:    "Expired token",
+			token:   hmacJWT(t, "s3cret", map[string]any{"exp": past}),
+			expect:  `valid: true`,
+			wantErr: "access_token: invalid jwt: token expired at",
+		},
+		{
+			name:    "Claim mismatch",
+			token:   hmacJWT(t, "s3cret", claims),
+			expect:  `claims: {sub: 42}`,
+			wantErr: `jwt claim not equal: access_token: expected claim sub to be 42, got "42"`,
+		},
+		{
+			name:    "Missing claim",
+			token:   hmacJWT(t, "s3cret", claims),
+			expect:  `claims: {email: a@example.com}`,
+			wantErr: "jwt claim not equal: access_token: expected claim email to be present",
+		},
+		{
+			name:    "Not a token",
+			token:   "abc",
+			expect:  `claims: {sub: "42"}`,
+			wantErr: "access_token: invalid jwt: expected 3 segments, got 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var expect app.JWTExpect
+			require.NoError(t, yaml.Unmarshal([]byte(tc.expect), &expect))
+
+			a := &app.Abdd{
+				Global:       app.Global{Config: app.Config{JWT: tc.config}},
+				LastResponse: &app.LastResponse{Body: toPointer(fmt.Sprintf(`{"access_token": %q}`, tc.token))},
+			}
+			err := a.ValidateResponse(&app.Test{Expect: app.TestExpect{JWT: map[string]app.JWTExpect{"access_token": expect}}})
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractJWTClaim(t *testing.T) {
+	token := hmacJWT(t, "s3cret", map[string]any{"sub": "42", "org": map[string]any{"id": 7}})
+	a := &app.Abdd{
+		Store:        map[string]any{},
+		LastResponse: &app.LastResponse{Body: toPointer(fmt.Sprintf(`{"access_token": %q}`, token))},
+	}
+
+	err := a.ExtractData(&app.Test{Extract: []app.TestExtract{
+		{Path: "access_token", JWTClaim: "sub", As: "userId"},
+		{Path: "access_token", JWTClaim: "org.id", As: "orgId"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "42", a.Store["userId"])
+	assert.Equal(t, json.Number("7"), a.Store["orgId"])
+
+	err = a.ExtractData(&app.Test{Extract: []app.TestExtract{{Path: "access_token", JWTClaim: "email", As: "email"}}})
+	assert.ErrorIs(t, err, app.ErrExtractionPathNotFound)
+
+	err = a.ValidateResponse(&app.Test{Expect: app.TestExpect{Assert: []string{"jwt(body.access_token).sub == '42'"}}})
+	assert.NoError(t, err)
+}
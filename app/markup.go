@@ -0,0 +1,831 @@
+package app
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	formatJSON = "json"
+	formatXML  = "xml"
+	formatHTML = "html"
+)
+
+// markupNode is an element or text node of a parsed XML or HTML document.
+// The document itself is an element without a name. Space is the namespace
+// URI of an XML element and Namespaces maps the prefixes in scope to URIs.
+type markupNode struct {
+	Name       string
+	Space      string
+	Attrs      map[string]string
+	Namespaces map[string]string
+	Text       string
+	IsText     bool
+	Parent     *markupNode
+	Children   []*markupNode
+}
+
+// parseMarkup parses an XML document, or an HTML document the way a browser
+// does when html is set.
+func parseMarkup(body string, isHTML bool) (*markupNode, error) {
+	if isHTML {
+		doc, err := html.Parse(strings.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse html: %w", err)
+		}
+		root := &markupNode{}
+		appendHTML(root, doc)
+		return root, nil
+	}
+
+	d := xml.NewDecoder(strings.NewReader(body))
+	root := &markupNode{Namespaces: map[string]string{}}
+	current := root
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse xml: %w", err)
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			node := &markupNode{
+				Name:       tok.Name.Local,
+				Space:      tok.Name.Space,
+				Attrs:      map[string]string{},
+				Namespaces: namespaceScope(current.Namespaces, tok.Attr),
+				Parent:     current,
+			}
+			for _, attr := range tok.Attr {
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				node.Attrs[attr.Name.Local] = attr.Value
+				if prefix, ok := node.prefix(attr.Name.Space); ok {
+					node.Attrs[prefix+":"+attr.Name.Local] = attr.Value
+				}
+			}
+			current.Children = append(current.Children, node)
+			current = node
+		case xml.EndElement:
+			if current.Parent != nil {
+				current = current.Parent
+			}
+		case xml.CharData:
+			current.Children = append(current.Children, &markupNode{Text: string(tok), IsText: true, Parent: current})
+		}
+	}
+
+	return root, nil
+}
+
+// appendHTML copies the elements and text below an html node into parent.
+func appendHTML(parent *markupNode, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.ElementNode:
+			node := &markupNode{Name: c.Data, Attrs: map[string]string{}, Parent: parent}
+			for _, attr := range c.Attr {
+				node.Attrs[attr.Key] = attr.Val
+			}
+			parent.Children = append(parent.Children, node)
+			appendHTML(node, c)
+		case html.TextNode:
+			parent.Children = append(parent.Children, &markupNode{Text: c.Data, IsText: true, Parent: parent})
+		}
+	}
+}
+
+// namespaceScope returns the prefixes in scope for an element: those of its
+// parent plus the ones its own attributes declare.
+func namespaceScope(parent map[string]string, attrs []xml.Attr) map[string]string {
+	var scope map[string]string
+	for _, attr := range attrs {
+		prefix := attr.Name.Local
+		if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+			prefix = ""
+		} else if attr.Name.Space != "xmlns" {
+			continue
+		}
+
+		if scope == nil {
+			scope = make(map[string]string, len(parent)+1)
+			for k, v := range parent {
+				scope[k] = v
+			}
+		}
+		scope[prefix] = attr.Value
+	}
+
+	if scope == nil {
+		return parent
+	}
+	return scope
+}
+
+// prefix returns a non-empty prefix bound to the namespace uri in the scope
+// of the node.
+func (n *markupNode) prefix(uri string) (string, bool) {
+	for _, p := range sortedStringKeys(n.Namespaces) {
+		if p != "" && n.Namespaces[p] == uri {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// matchesName reports whether an element matches an XPath name test. An
+// unprefixed name matches the local name in any namespace; a prefixed name
+// also requires the namespace the prefix is bound to in the document.
+func (n *markupNode) matchesName(test string) bool {
+	prefix, local, ok := strings.Cut(test, ":")
+	if !ok {
+		return test == "*" || n.Name == test
+	}
+	if local != "*" && n.Name != local {
+		return false
+	}
+	uri, ok := n.Namespaces[prefix]
+	return ok && n.Space == uri
+}
+
+// elements returns the element children of the node.
+func (n *markupNode) elements() []*markupNode {
+	var elements []*markupNode
+	for _, child := range n.Children {
+		if !child.IsText {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+// descendants returns every element below the node in document order.
+func (n *markupNode) descendants() []*markupNode {
+	var all []*markupNode
+	for _, child := range n.elements() {
+		all = append(all, child)
+		all = append(all, child.descendants()...)
+	}
+	return all
+}
+
+// textContent returns the trimmed text of the node and all its descendants.
+func (n *markupNode) textContent() string {
+	if n.IsText {
+		return n.Text
+	}
+
+	var b strings.Builder
+	var collect func(*markupNode)
+	collect = func(node *markupNode) {
+		for _, child := range node.Children {
+			if child.IsText {
+				b.WriteString(child.Text)
+			} else {
+				collect(child)
+			}
+		}
+	}
+	collect(n)
+	return strings.TrimSpace(b.String())
+}
+
+// ownText returns the trimmed text directly inside the node.
+func (n *markupNode) ownText() string {
+	var b strings.Builder
+	for _, child := range n.Children {
+		if child.IsText {
+			b.WriteString(child.Text)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// bodyFormat picks how a body is queried: an explicit format wins, otherwise
+// the content type decides and JSON is the fallback.
+func bodyFormat(format, contentType string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "text/html"):
+		return formatHTML
+	case strings.Contains(contentType, "xml"):
+		return formatXML
+	}
+	return formatJSON
+}
+
+// selectMarkup evaluates an XPath expression against XML or a CSS selector
+// against HTML and returns the string value of the first match.
+func selectMarkup(body, format, expr string) (string, bool, error) {
+	root, err := parseMarkup(body, format == formatHTML)
+	if err != nil {
+		return "", false, err
+	}
+
+	if format == formatHTML {
+		return selectCSS(root, expr)
+	}
+	return evaluateXPath(root, expr)
+}
+
+var xpathCount = regexp.MustCompile(`^count\((.+)\)$`)
+
+// evaluateXPath supports absolute and relative location paths with child (/)
+// and descendant (//) steps, name tests with optional prefixes bound by the
+// document, *, @attr, text(), . and .., positional and comparison predicates,
+// and count().
+func evaluateXPath(root *markupNode, expr string) (string, bool, error) {
+	expr = strings.TrimSpace(expr)
+	if m := xpathCount.FindStringSubmatch(expr); m != nil {
+		items, err := xpathSelect(root, m[1])
+		if err != nil {
+			return "", false, err
+		}
+		return strconv.Itoa(len(items)), true, nil
+	}
+
+	items, err := xpathSelect(root, expr)
+	if err != nil || len(items) == 0 {
+		return "", false, err
+	}
+	return items[0].value(), true, nil
+}
+
+type xpathItem struct {
+	node  *markupNode
+	text  string
+	isStr bool
+}
+
+func (i xpathItem) value() string {
+	if i.isStr {
+		return i.text
+	}
+	return i.node.textContent()
+}
+
+type xpathStep struct {
+	descendant bool
+	test       string
+	predicates []string
+}
+
+func parseXPath(expr string) ([]xpathStep, error) {
+	var steps []xpathStep
+	i := 0
+	for i < len(expr) {
+		step := xpathStep{}
+		switch {
+		case strings.HasPrefix(expr[i:], "//"):
+			step.descendant = true
+			i += 2
+		case expr[i] == '/':
+			i++
+		case i > 0:
+			return nil, fmt.Errorf("invalid xpath %s", expr)
+		}
+
+		start := i
+		for i < len(expr) && expr[i] != '/' && expr[i] != '[' {
+			if expr[i] == '(' {
+				for i < len(expr) && expr[i] != ')' {
+					i++
+				}
+			}
+			i++
+		}
+		step.test = strings.TrimSpace(expr[start:min(i, len(expr))])
+
+		for i < len(expr) && expr[i] == '[' {
+			depth, end := 0, -1
+			var quote byte
+			for j := i; j < len(expr); j++ {
+				c := expr[j]
+				switch {
+				case quote != 0:
+					if c == quote {
+						quote = 0
+					}
+				case c == '\'' || c == '"':
+					quote = c
+				case c == '[':
+					depth++
+				case c == ']':
+					depth--
+					if depth == 0 {
+						end = j
+					}
+				}
+				if end >= 0 {
+					break
+				}
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("invalid xpath %s: unclosed predicate", expr)
+			}
+			step.predicates = append(step.predicates, strings.TrimSpace(expr[i+1:end]))
+			i = end + 1
+		}
+
+		if step.test == "" {
+			return nil, fmt.Errorf("invalid xpath %s: empty step", expr)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func xpathSelect(root *markupNode, expr string) ([]xpathItem, error) {
+	steps, err := parseXPath(strings.TrimSpace(expr))
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("invalid xpath %s", expr)
+	}
+
+	context := []xpathItem{{node: root}}
+	for _, step := range steps {
+		var next []xpathItem
+		seen := map[*markupNode]bool{}
+
+		for _, item := range context {
+			if item.isStr {
+				return nil, fmt.Errorf("invalid xpath %s: cannot select below an attribute or text", expr)
+			}
+
+			// Predicates such as [1] and [last()] count within each parent.
+			for _, candidates := range xpathCandidates(item.node, step) {
+				for _, predicate := range step.predicates {
+					candidates, err = xpathFilter(candidates, predicate)
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				for _, c := range candidates {
+					if c.isStr {
+						next = append(next, c)
+					} else if !seen[c.node] {
+						seen[c.node] = true
+						next = append(next, c)
+					}
+				}
+			}
+		}
+		context = next
+	}
+	return context, nil
+}
+
+// xpathCandidates returns the items a step selects from the node, grouped by
+// the node they were selected from.
+func xpathCandidates(node *markupNode, step xpathStep) [][]xpathItem {
+	scope := []*markupNode{node}
+	if step.descendant {
+		scope = append(scope, node.descendants()...)
+	}
+
+	var groups [][]xpathItem
+	for _, n := range scope {
+		var items []xpathItem
+		switch {
+		case step.test == ".":
+			items = append(items, xpathItem{node: n})
+		case step.test == "..":
+			if n.Parent != nil {
+				items = append(items, xpathItem{node: n.Parent})
+			}
+		case step.test == "text()":
+			for _, child := range n.Children {
+				if child.IsText && strings.TrimSpace(child.Text) != "" {
+					items = append(items, xpathItem{text: strings.TrimSpace(child.Text), isStr: true})
+				}
+			}
+		case strings.HasPrefix(step.test, "@"):
+			name := step.test[1:]
+			for _, key := range sortedAttrs(n) {
+				if name == "*" || name == key {
+					items = append(items, xpathItem{text: n.Attrs[key], isStr: true})
+				}
+			}
+		default:
+			for _, child := range n.elements() {
+				if child.matchesName(step.test) {
+					items = append(items, xpathItem{node: child})
+				}
+			}
+		}
+		if len(items) > 0 {
+			groups = append(groups, items)
+		}
+	}
+	return groups
+}
+
+var (
+	xpathFunction   = regexp.MustCompile(`^(contains|starts-with)\(\s*(.+?)\s*,\s*('[^']*'|"[^"]*")\s*\)$`)
+	xpathComparison = regexp.MustCompile(`^(.+?)\s*(!=|=)\s*('[^']*'|"[^"]*"|-?[0-9.]+)$`)
+)
+
+func xpathFilter(items []xpathItem, predicate string) ([]xpathItem, error) {
+	if n, err := strconv.Atoi(predicate); err == nil {
+		if n >= 1 && n <= len(items) {
+			return []xpathItem{items[n-1]}, nil
+		}
+		return nil, nil
+	}
+	if predicate == "last()" {
+		if len(items) == 0 {
+			return nil, nil
+		}
+		return items[len(items)-1:], nil
+	}
+
+	var filtered []xpathItem
+	for _, item := range items {
+		if item.isStr {
+			continue
+		}
+
+		ok, err := xpathPredicate(item.node, predicate)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered, nil
+}
+
+func xpathPredicate(node *markupNode, predicate string) (bool, error) {
+	if m := xpathFunction.FindStringSubmatch(predicate); m != nil {
+		value, ok := xpathOperand(node, m[2])
+		literal := unquote(m[3])
+		if m[1] == "contains" {
+			return ok && strings.Contains(value, literal), nil
+		}
+		return ok && strings.HasPrefix(value, literal), nil
+	}
+
+	if m := xpathComparison.FindStringSubmatch(predicate); m != nil {
+		value, ok := xpathOperand(node, m[1])
+		equal := ok && value == unquote(m[3])
+		if m[2] == "!=" {
+			return ok && !equal, nil
+		}
+		return equal, nil
+	}
+
+	if strings.ContainsAny(predicate, "()=<>") {
+		return false, fmt.Errorf("unsupported xpath predicate [%s]", predicate)
+	}
+
+	_, ok := xpathOperand(node, predicate)
+	return ok, nil
+}
+
+// xpathOperand returns the string value of @attr, text(), . or a child element.
+func xpathOperand(node *markupNode, operand string) (string, bool) {
+	operand = strings.TrimSpace(operand)
+	switch {
+	case strings.HasPrefix(operand, "@"):
+		value, ok := node.Attrs[operand[1:]]
+		return value, ok
+	case operand == "text()":
+		return node.ownText(), true
+	case operand == ".":
+		return node.textContent(), true
+	}
+
+	for _, child := range node.elements() {
+		if child.matchesName(operand) {
+			return child.textContent(), true
+		}
+	}
+	return "", false
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func sortedAttrs(n *markupNode) []string {
+	attrs := make(map[string]any, len(n.Attrs))
+	for k := range n.Attrs {
+		attrs[k] = nil
+	}
+	return sortedKeys(attrs)
+}
+
+// cssCompound is a run of simple selectors such as a.button[href] with the
+// combinator that links it to the compound on its left.
+type cssCompound struct {
+	combinator byte
+	tag        string
+	id         string
+	classes    []string
+	attrs      []cssAttr
+	pseudos    []string
+}
+
+type cssAttr struct {
+	name  string
+	op    string
+	value string
+}
+
+var cssAttribute = regexp.MustCompile(`^\[\s*([\w:-]+)\s*(?:([~^$*|]?=)\s*('[^']*'|"[^"]*"|[^\]\s]+))?\s*\]`)
+
+// selectCSS supports type, #id, .class and [attr] selectors with the =, ~=,
+// ^=, $=, *= and |= operators, :first-child, :last-child and :nth-child(n),
+// descendant and child (>) combinators and selector lists. A trailing @attr
+// selects an attribute of the first match instead of its text.
+func selectCSS(root *markupNode, expr string) (string, bool, error) {
+	selector, attr := expr, ""
+	if i := strings.LastIndex(expr, "@"); i > strings.LastIndex(expr, "]") {
+		selector, attr = expr[:i], expr[i+1:]
+	}
+
+	var parts [][]cssCompound
+	for _, part := range splitSelectorList(selector) {
+		compounds, err := parseCSS(strings.TrimSpace(part))
+		if err != nil {
+			return "", false, err
+		}
+		parts = append(parts, compounds)
+	}
+
+	// The first element in document order that matches any part wins.
+	var first *markupNode
+	for _, n := range root.descendants() {
+		for _, compounds := range parts {
+			if cssMatches(n, compounds) {
+				first = n
+				break
+			}
+		}
+		if first != nil {
+			break
+		}
+	}
+	if first == nil {
+		return "", false, nil
+	}
+
+	if attr != "" {
+		value, ok := first.Attrs[attr]
+		return value, ok, nil
+	}
+	return first.textContent(), true, nil
+}
+
+// splitSelectorList splits a selector list on the commas outside attribute
+// selectors, parentheses and quotes.
+func splitSelectorList(selector string) []string {
+	var parts []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(selector); i++ {
+		c := selector[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, selector[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, selector[start:])
+}
+
+func parseCSS(selector string) ([]cssCompound, error) {
+	if selector == "" {
+		return nil, fmt.Errorf("empty css selector")
+	}
+
+	var compounds []cssCompound
+	combinator := byte(' ')
+	i := 0
+	for i < len(selector) {
+		switch c := selector[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+			continue
+		case c == '>':
+			combinator = '>'
+			i++
+			continue
+		}
+
+		compound := cssCompound{combinator: combinator}
+		combinator = ' '
+		start := i
+		for i < len(selector) && isCSSIdent(selector[i]) {
+			i++
+		}
+		compound.tag = strings.ToLower(selector[start:i])
+
+		for i < len(selector) {
+			c := selector[i]
+			if c == ' ' || c == '>' || c == '\t' || c == '\n' {
+				break
+			}
+
+			switch c {
+			case '#', '.':
+				start := i + 1
+				i++
+				for i < len(selector) && isCSSIdent(selector[i]) {
+					i++
+				}
+				if c == '#' {
+					compound.id = selector[start:i]
+				} else {
+					compound.classes = append(compound.classes, selector[start:i])
+				}
+			case '[':
+				m := cssAttribute.FindStringSubmatch(selector[i:])
+				if m == nil {
+					return nil, fmt.Errorf("invalid css attribute selector in %s", selector)
+				}
+				compound.attrs = append(compound.attrs, cssAttr{name: strings.ToLower(m[1]), op: m[2], value: unquote(m[3])})
+				i += len(m[0])
+			case ':':
+				start := i + 1
+				i++
+				for i < len(selector) && (isCSSIdent(selector[i]) || selector[i] == '(' || selector[i] == ')') {
+					i++
+				}
+				compound.pseudos = append(compound.pseudos, selector[start:i])
+			default:
+				return nil, fmt.Errorf("unsupported css selector %s", selector)
+			}
+		}
+		compounds = append(compounds, compound)
+	}
+	return compounds, nil
+}
+
+func isCSSIdent(c byte) bool {
+	return c == '-' || c == '_' || c == '*' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// cssMatches checks the compounds right to left against the node and its ancestors.
+func cssMatches(node *markupNode, compounds []cssCompound) bool {
+	last := len(compounds) - 1
+	if !compounds[last].matches(node) {
+		return false
+	}
+	if last == 0 {
+		return true
+	}
+
+	rest := compounds[:last]
+	if compounds[last].combinator == '>' {
+		return node.Parent != nil && node.Parent.Name != "" && cssMatches(node.Parent, rest)
+	}
+	for p := node.Parent; p != nil && p.Name != ""; p = p.Parent {
+		if cssMatches(p, rest) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c cssCompound) matches(n *markupNode) bool {
+	if c.tag != "" && c.tag != "*" && c.tag != n.Name {
+		return false
+	}
+	if c.id != "" && n.Attrs["id"] != c.id {
+		return false
+	}
+
+	classes := strings.Fields(n.Attrs["class"])
+	for _, class := range c.classes {
+		if !containsString(classes, class) {
+			return false
+		}
+	}
+
+	for _, attr := range c.attrs {
+		value, ok := n.Attrs[attr.name]
+		if !ok || !attr.matches(value) {
+			return false
+		}
+	}
+
+	for _, pseudo := range c.pseudos {
+		if !cssPseudo(n, pseudo) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a cssAttr) matches(value string) bool {
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return value == a.value
+	case "~=":
+		return containsString(strings.Fields(value), a.value)
+	case "^=":
+		return strings.HasPrefix(value, a.value)
+	case "$=":
+		return strings.HasSuffix(value, a.value)
+	case "*=":
+		return strings.Contains(value, a.value)
+	case "|=":
+		return value == a.value || strings.HasPrefix(value, a.value+"-")
+	}
+	return false
+}
+
+func cssPseudo(n *markupNode, pseudo string) bool {
+	siblings := n.Parent.elements()
+	index := 0
+	for i, s := range siblings {
+		if s == n {
+			index = i + 1
+		}
+	}
+
+	switch {
+	case pseudo == "first-child":
+		return index == 1
+	case pseudo == "last-child":
+		return index == len(siblings)
+	case strings.HasPrefix(pseudo, "nth-child(") && strings.HasSuffix(pseudo, ")"):
+		arg := pseudo[len("nth-child(") : len(pseudo)-1]
+		switch arg {
+		case "odd":
+			return index%2 == 1
+		case "even":
+			return index%2 == 0
+		}
+		n, err := strconv.Atoi(arg)
+		return err == nil && index == n
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMarkup checks XPath or CSS expectations against the response body.
+func (a *Abdd) validateMarkup(expectations map[string]any, format string) error {
+	body := ""
+	if a.LastResponse.Body != nil {
+		body = *a.LastResponse.Body
+	}
+
+	for _, expr := range sortedKeys(expectations) {
+		expected := fmt.Sprintf("%v", expectations[expr])
+		actual, ok, err := selectMarkup(body, format, expr)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: expected %s to be present", ErrSelectorNotFound, expr)
+		}
+		if actual != expected {
+			return fmt.Errorf("%w: expected %s to be %s, got %s", ErrSelectorNotEqual, expr, expected, actual)
+		}
+	}
+	return nil
+}
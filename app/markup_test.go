@@ -0,0 +1,276 @@
+package app_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const soapBody = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <GetUsersResponse>
+      <user id="1" status="active"><name>John</name><role>admin</role></user>
+      <user id="2" status="inactive"><name>Jane</name><role>user</role></user>
+      <user id="3" status="active"><name>Bob</name><role>user</role></user>
+      <total>3</total>
+    </GetUsersResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+const htmlBody = `<!DOCTYPE html>
+<html>
+<head><title>Users &amp; roles</title></head>
+<body>
+  <h1 id="title" class="page-title">Users</h1>
+  <ul class="users">
+    <li class="user active" data-id="1"><a href="/users/1" title="John, admin">John</a></li>
+    <li class="user" data-id="2"><a href="/users/2">Jane</a><br></li>
+    <li class="user active" data-id="3"><a href="/users/3">Bob</a></li>
+  </ul>
+  <p>Total: <b>3</b>
+</body>
+</html>`
+
+func TestValidateResponseXML(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expect  map[string]any
+		wantErr string
+	}{
+		{name: "Absolute path", expect: map[string]any{"/Envelope/Body/GetUsersResponse/total": 3}},
+		{name: "Descendant path", expect: map[string]any{"//user/name": "John"}},
+		{name: "Position", expect: map[string]any{"//user[2]/name": "Jane", "//user[last()]/name": "Bob"}},
+		{name: "Attribute", expect: map[string]any{"//user[name='Jane']/@status": "inactive"}},
+		{name: "Attribute predicate", expect: map[string]any{"//user[@id=\"3\"]/role": "user"}},
+		{name: "Combined predicates", expect: map[string]any{"//user[@status='active'][role='user']/name": "Bob"}},
+		{name: "Functions", expect: map[string]any{"//user[starts-with(name, 'Ja')]/@id": 2, "//user[contains(@status, 'in')]/name": "Jane"}},
+		{name: "Count", expect: map[string]any{"count(//user[@status='active'])": 2}},
+		{name: "Text", expect: map[string]any{"//user[1]/name/text()": "John"}},
+		{name: "Wildcard and parent", expect: map[string]any{"//name[.='Bob']/../*[2]": "user"}},
+		{
+			name:    "Mismatch",
+			expect:  map[string]any{"//user[1]/name": "Jane"},
+			wantErr: "selector not equal: expected //user[1]/name to be Jane, got John",
+		},
+		{
+			name:    "Not found",
+			expect:  map[string]any{"//account": "x"},
+			wantErr: "selector not found: expected //account to be present",
+		},
+		{
+			name:    "Unsupported predicate",
+			expect:  map[string]any{"//user[position() > 1]": "x"},
+			wantErr: "unsupported xpath predicate [position() > 1]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app.Abdd{LastResponse: &app.LastResponse{Body: toPointer(soapBody)}}
+			err := a.ValidateResponse(&app.Test{Expect: app.TestExpect{XML: tc.expect}})
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+
+	// Positions count within each parent, not across the whole document.
+	lists := `<r><list><item>a</item><item>b</item></list><list><item>c</item><item>d</item></list></r>`
+	a := &app.Abdd{LastResponse: &app.LastResponse{Body: toPointer(lists)}}
+	err := a.ValidateResponse(&app.Test{Expect: app.TestExpect{XML: map[string]any{
+		"count(//item[1])":       2,
+		"count(/r/list/item[2])": 2,
+		"//item[last()]":         "b",
+		"//list[2]/item[1]":      "c",
+		"/r/list/item[2]":        "b",
+	}}})
+	assert.NoError(t, err)
+
+	a = &app.Abdd{LastResponse: &app.LastResponse{Body: toPointer("<a><b></a>")}}
+	err = a.ValidateResponse(&app.Test{Expect: app.TestExpect{XML: map[string]any{"//b": ""}}})
+	assert.ErrorContains(t, err, "failed to parse xml")
+}
+
+const soapNamespacedBody = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <soap:Header><m:Trace xmlns:m="urn:orders">abc</m:Trace></soap:Header>
+  <soap:Body>
+    <m:CreateOrderResponse xmlns:m="urn:orders">
+      <m:Id>42</m:Id>
+      <m:Status xsi:type="xsd:string">created</m:Status>
+      <Lines xmlns="urn:lines"><Line sku="A1">2</Line></Lines>
+    </m:CreateOrderResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestValidateResponseXMLNamespaces(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expect  map[string]any
+		wantErr string
+	}{
+		{name: "Prefixed steps", expect: map[string]any{"/soap:Envelope/soap:Body/m:CreateOrderResponse/m:Id": 42}},
+		{name: "Prefixed descendant", expect: map[string]any{"//soap:Body//m:Id": 42, "//m:Id": 42}},
+		{name: "Local name", expect: map[string]any{"//Id": 42, "//Line/@sku": "A1"}},
+		{name: "Prefixed wildcard", expect: map[string]any{"//soap:Header/m:*": "abc"}},
+		{name: "Prefixed attribute", expect: map[string]any{"//m:Status/@xsi:type": "xsd:string"}},
+		{name: "Prefixed predicate", expect: map[string]any{"//m:CreateOrderResponse[m:Status='created']/m:Id": 42}},
+		{
+			name:    "Wrong namespace",
+			expect:  map[string]any{"//soap:Id": 42},
+			wantErr: "selector not found: expected //soap:Id to be present",
+		},
+		{
+			name:    "Unbound prefix",
+			expect:  map[string]any{"//x:Id": 42},
+			wantErr: "selector not found: expected //x:Id to be present",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app.Abdd{LastResponse: &app.LastResponse{Body: toPointer(soapNamespacedBody)}}
+			err := a.ValidateResponse(&app.Test{Expect: app.TestExpect{XML: tc.expect}})
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateResponseHTML(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expect  map[string]any
+		wantErr string
+	}{
+		{name: "Type selector with entity", expect: map[string]any{"title": "Users & roles"}},
+		{name: "Id and class", expect: map[string]any{"#title": "Users", "h1.page-title": "Users"}},
+		{name: "Multiple classes", expect: map[string]any{"li.user.active": "John"}},
+		{name: "Attribute selectors", expect: map[string]any{"li[data-id='2']": "Jane", "a[href$=\"/3\"]": "Bob", "a[href^=/users]": "John"}},
+		{name: "Combinators", expect: map[string]any{"ul.users > li:last-child a": "Bob", "body b": "3"}},
+		{name: "Nth child", expect: map[string]any{"li:nth-child(2)": "Jane", "li:first-child@data-id": 1}},
+		{name: "Attribute value", expect: map[string]any{"li.user:nth-child(3) a@href": "/users/3"}},
+		{name: "Selector list", expect: map[string]any{"table, h1": "Users"}},
+		{name: "Selector list in document order", expect: map[string]any{"h1, title": "Users & roles"}},
+		{name: "Comma inside attribute value", expect: map[string]any{"a[title=\"John, admin\"]": "John", "table, a[title='John, admin']@href": "/users/1"}},
+		{
+			name:    "Mismatch",
+			expect:  map[string]any{"li.active a": "Jane"},
+			wantErr: "selector not equal: expected li.active a to be Jane, got John",
+		},
+		{
+			name:    "Not found",
+			expect:  map[string]any{"ul > a": "John"},
+			wantErr: "selector not found: expected ul > a to be present",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app.Abdd{LastResponse: &app.LastResponse{Body: toPointer(htmlBody)}}
+			err := a.ValidateResponse(&app.Test{Expect: app.TestExpect{HTML: tc.expect}})
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateResponseHTMLParsing(t *testing.T) {
+	testCases := []struct {
+		name   string
+		body   string
+		expect map[string]any
+	}{
+		{
+			name:   "Implied end tags",
+			body:   `<p>one<p>two<ul><li>a<li>b</ul><h1>Title</h1>`,
+			expect: map[string]any{"p": "one", "p:nth-child(2)": "two", "li:last-child": "b", "body > h1": "Title"},
+		},
+		{
+			name:   "Script with markup characters",
+			body:   `<html><head><script>if (a < b && c > d) { x = "</p>"; }</script></head><body><h1>Title</h1><p id="after">ok</p></body></html>`,
+			expect: map[string]any{"body > h1": "Title", "#after": "ok"},
+		},
+		{
+			name:   "Unquoted attributes and void elements",
+			body:   `<form action=/save><input name=q value=x><img src=a.png><button>Go</button></form>`,
+			expect: map[string]any{"input@value": "x", "form > button": "Go", "img@src": "a.png"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app.Abdd{LastResponse: &app.LastResponse{Body: toPointer(tc.body)}}
+			err := a.ValidateResponse(&app.Test{Expect: app.TestExpect{HTML: tc.expect}})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestExtractDataMarkup(t *testing.T) {
+	testCases := []struct {
+		name    string
+		body    string
+		headers http.Header
+		extract app.TestExtract
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "XML by content type",
+			body:    soapBody,
+			headers: http.Header{"Content-Type": []string{"text/xml; charset=utf-8"}},
+			extract: app.TestExtract{Path: "//user[2]/@id", As: "value"},
+			want:    "2",
+		},
+		{
+			name:    "HTML by content type",
+			body:    htmlBody,
+			headers: http.Header{"Content-Type": []string{"text/html"}},
+			extract: app.TestExtract{Path: "li.active a@href", As: "value"},
+			want:    "/users/1",
+		},
+		{
+			name:    "Explicit format",
+			body:    soapBody,
+			headers: http.Header{"Content-Type": []string{"application/octet-stream"}},
+			extract: app.TestExtract{Path: "//total", As: "value", Format: "xml"},
+			want:    "3",
+		},
+		{
+			name:    "Missing selector",
+			body:    htmlBody,
+			extract: app.TestExtract{Path: "table td", As: "value", Format: "html"},
+			wantErr: "extraction path not found: expected table td to be present",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app.Abdd{
+				Store:        map[string]any{},
+				LastResponse: &app.LastResponse{Body: toPointer(tc.body), RawHeaders: tc.headers},
+			}
+
+			err := a.ExtractData(&app.Test{Extract: []app.TestExtract{tc.extract}})
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, a.Store["value"])
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// OpenAPISpec is a loaded OpenAPI 3 document indexed by operation.
+type OpenAPISpec struct {
+	Operations []*OpenAPIOperation
+
+	doc       map[string]any
+	basePaths []string
+}
+
+// OpenAPIOperation is a single method and path template from the spec.
+type OpenAPIOperation struct {
+	Method string
+	Path   string
+
+	segments  []string
+	responses map[string]any
+}
+
+func (o *OpenAPIOperation) String() string {
+	return o.Method + " " + o.Path
+}
+
+// Statuses returns the documented response codes of the operation, such as 200, 4XX or default.
+func (o *OpenAPIOperation) Statuses() []string {
+	statuses := make([]string, 0, len(o.responses))
+	for status := range o.responses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// LoadOpenAPI reads an OpenAPI 3 document in JSON or YAML.
+func LoadOpenAPI(path string) (*OpenAPISpec, error) {
+	raw, err := loadDocument(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("openapi document %s is not an object", path)
+	}
+	if version, _ := doc["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("openapi document %s is not an OpenAPI 3 specification", path)
+	}
+
+	spec := &OpenAPISpec{doc: doc}
+
+	servers, _ := doc["servers"].([]any)
+	for _, s := range servers {
+		server, _ := s.(map[string]any)
+		serverURL, _ := server["url"].(string)
+		u, err := url.Parse(serverURL)
+		if err != nil {
+			continue
+		}
+		if base := strings.TrimSuffix(u.Path, "/"); base != "" {
+			spec.basePaths = append(spec.basePaths, base)
+		}
+	}
+
+	paths, _ := doc["paths"].(map[string]any)
+	for _, path := range sortedKeys(paths) {
+		item, _ := paths[path].(map[string]any)
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			responses, _ := op["responses"].(map[string]any)
+			spec.Operations = append(spec.Operations, &OpenAPIOperation{
+				Method:    strings.ToUpper(method),
+				Path:      path,
+				segments:  strings.Split(strings.Trim(path, "/"), "/"),
+				responses: responses,
+			})
+		}
+	}
+
+	return spec, nil
+}
+
+// Match finds the operation for the request method and path. Paths are tried
+// as given and with any server base path removed; literal segments win over
+// templated ones.
+func (s *OpenAPISpec) Match(method, path string) *OpenAPIOperation {
+	candidates := []string{path}
+	for _, base := range s.basePaths {
+		if strings.HasPrefix(path, base) {
+			candidates = append(candidates, strings.TrimPrefix(path, base))
+		}
+	}
+
+	var best *OpenAPIOperation
+	bestParams := -1
+	for _, candidate := range candidates {
+		segments := strings.Split(strings.Trim(candidate, "/"), "/")
+		for _, op := range s.Operations {
+			if op.Method != strings.ToUpper(method) {
+				continue
+			}
+
+			params, ok := matchSegments(op.segments, segments)
+			if ok && (best == nil || params < bestParams) {
+				best, bestParams = op, params
+			}
+		}
+	}
+	return best
+}
+
+func matchSegments(template, segments []string) (int, bool) {
+	if len(template) != len(segments) {
+		return 0, false
+	}
+
+	params := 0
+	for i, t := range template {
+		if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+			if segments[i] == "" {
+				return 0, false
+			}
+			params++
+			continue
+		}
+		if t != segments[i] {
+			return 0, false
+		}
+	}
+	return params, true
+}
+
+// response returns the response object documented for the status code,
+// falling back to range keys such as 2XX and then default.
+func (s *OpenAPISpec) response(op *OpenAPIOperation, status int) (map[string]any, bool) {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if r, ok := op.responses[key]; ok {
+			resolved := s.resolve(r)
+			response, _ := resolved.(map[string]any)
+			return response, true
+		}
+	}
+	return nil, false
+}
+
+// resolve follows $ref pointers within the spec.
+func (s *OpenAPISpec) resolve(node any) any {
+	for i := 0; i < 32; i++ {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return node
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return node
+		}
+		target, err := resolvePointer(s.doc, ref)
+		if err != nil {
+			return node
+		}
+		node = target
+	}
+	return node
+}
+
+func (a *Abdd) validateOpenAPI(t *Test) error {
+	lr := a.LastResponse
+	if t.Expect.SkipOpenAPI || lr.Code == nil {
+		return nil
+	}
+
+	op := lr.Operation
+	if op == nil {
+		return fmt.Errorf("%w: %s %s", ErrOpenAPIUndocumented, lr.Method, lr.Path)
+	}
+
+	response, ok := a.OpenAPI.response(op, *lr.Code)
+	if !ok {
+		return fmt.Errorf("%w: status %d is not documented for %s", ErrOpenAPIMismatch, *lr.Code, op)
+	}
+
+	content, _ := response["content"].(map[string]any)
+	if len(content) == 0 || lr.Body == nil || *lr.Body == "" {
+		return nil
+	}
+
+	contentType := lr.header("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: invalid content type %q for %s", ErrOpenAPIMismatch, contentType, op)
+	}
+
+	media, ok := matchMediaType(content, mediaType)
+	if !ok {
+		return fmt.Errorf("%w: content type %s is not documented for %s %d", ErrOpenAPIMismatch, mediaType, op, *lr.Code)
+	}
+
+	schema, ok := media["schema"]
+	if !ok || !isJSONMediaType(mediaType) {
+		return nil
+	}
+
+	var body any
+	if err := json.Unmarshal([]byte(*lr.Body), &body); err != nil {
+		return fmt.Errorf("%w: response body of %s is not valid json", ErrOpenAPIMismatch, op)
+	}
+
+	if errs := ValidateSchema(a.OpenAPI.doc, schema, body); len(errs) > 0 {
+		return fmt.Errorf("%w: %s %d: %w", ErrOpenAPIMismatch, op, *lr.Code, schemaErrors(errs))
+	}
+	return nil
+}
+
+func matchMediaType(content map[string]any, mediaType string) (map[string]any, bool) {
+	wildcard := strings.SplitN(mediaType, "/", 2)[0] + "/*"
+	for _, key := range []string{mediaType, wildcard, "*/*"} {
+		for name, media := range content {
+			if strings.EqualFold(strings.TrimSpace(strings.SplitN(name, ";", 2)[0]), key) {
+				m, _ := media.(map[string]any)
+				return m, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,195 @@
+package app_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const openAPISpec = `openapi: 3.0.3
+info:
+  title: Users
+  version: "1"
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: List users
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/User"
+    post:
+      responses:
+        "201":
+          $ref: "#/components/responses/User"
+        4XX:
+          description: Invalid user
+  /users/{id}:
+    get:
+      responses:
+        "200":
+          $ref: "#/components/responses/User"
+  /users/me:
+    get:
+      responses:
+        "200":
+          $ref: "#/components/responses/User"
+components:
+  responses:
+    User:
+      description: A user
+      content:
+        application/json:
+          schema:
+            $ref: "#/components/schemas/User"
+  schemas:
+    User:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+        email:
+          type: string
+          nullable: true
+`
+
+func writeOpenAPISpec(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(openAPISpec), 0o644))
+	return path
+}
+
+func TestLoadOpenAPI(t *testing.T) {
+	spec, err := app.LoadOpenAPI(writeOpenAPISpec(t))
+	require.NoError(t, err)
+	assert.Len(t, spec.Operations, 4)
+
+	testCases := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{method: "GET", path: "/users", want: "GET /users"},
+		{method: "GET", path: "/v1/users/42", want: "GET /users/{id}"},
+		{method: "GET", path: "/users/me", want: "GET /users/me"},
+		{method: "post", path: "/users", want: "POST /users"},
+		{method: "DELETE", path: "/users/42"},
+		{method: "GET", path: "/accounts"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			op := spec.Match(tc.method, tc.path)
+			if tc.want == "" {
+				assert.Nil(t, op)
+				return
+			}
+			require.NotNil(t, op)
+			assert.Equal(t, tc.want, op.String())
+		})
+	}
+
+	notOpenAPI := filepath.Join(t.TempDir(), "swagger.yaml")
+	require.NoError(t, os.WriteFile(notOpenAPI, []byte("swagger: \"2.0\"\n"), 0o644))
+	_, err = app.LoadOpenAPI(notOpenAPI)
+	assert.ErrorContains(t, err, "is not an OpenAPI 3 specification")
+}
+
+func TestValidateResponseOpenAPI(t *testing.T) {
+	spec, err := app.LoadOpenAPI(writeOpenAPISpec(t))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/users/1":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"id": 1, "name": "John", "email": null}`))
+		case "/v1/users/2":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id": "2"}`))
+		case "/v1/users/3":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<p>John</p>`))
+		case "/v1/users/4":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name    string
+		test    app.Test
+		wantErr string
+	}{
+		{
+			name: "Conforming response",
+			test: app.Test{Request: &app.TestRequest{Method: "GET", URL: "/users/1"}},
+		},
+		{
+			name:    "Body does not match schema",
+			test:    app.Test{Request: &app.TestRequest{Method: "GET", URL: "/users/2"}},
+			wantErr: `response does not match openapi spec: GET /users/{id} 200: response does not match schema: /: missing required property "name" (/$ref/required); /id: expected type integer, got string (/$ref/properties/id/type)`,
+		},
+		{
+			name:    "Undocumented content type",
+			test:    app.Test{Request: &app.TestRequest{Method: "GET", URL: "/users/3"}},
+			wantErr: "response does not match openapi spec: content type text/html is not documented for GET /users/{id} 200",
+		},
+		{
+			name:    "Undocumented status code",
+			test:    app.Test{Request: &app.TestRequest{Method: "GET", URL: "/users/4"}},
+			wantErr: "response does not match openapi spec: status 404 is not documented for GET /users/{id}",
+		},
+		{
+			name: "Status range",
+			test: app.Test{Request: &app.TestRequest{Method: "POST", URL: "/users"}},
+		},
+		{
+			name:    "Undocumented endpoint",
+			test:    app.Test{Request: &app.TestRequest{Method: "DELETE", URL: "/users/1"}},
+			wantErr: "endpoint not documented in openapi spec: DELETE /v1/users/1",
+		},
+		{
+			name: "Skipped validation",
+			test: app.Test{
+				Request: &app.TestRequest{Method: "DELETE", URL: "/users/1"},
+				Expect:  app.TestExpect{SkipOpenAPI: true},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app.Abdd{
+				Global:  app.Global{Config: app.Config{BaseURL: server.URL + "/v1"}},
+				Client:  server.Client(),
+				OpenAPI: spec,
+			}
+
+			require.NoError(t, a.MakeRequest(&tc.test))
+			err := a.ValidateResponse(&tc.test)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
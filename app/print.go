@@ -1,6 +1,9 @@
 package app
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func (a *Abdd) PrintStartTest(t *Test) {
 	fmt.Printf("\n%s %s\n", infoText("▶"), t.Name)
@@ -24,7 +27,14 @@ func (a *Abdd) PrintExecuteCommandStep(t *Test) {
 
 func (a *Abdd) PrintMakeRequestStep(t *Test) {
 	fmt.Printf("  %s Made request\n", infoText("•"))
-	fmt.Printf("  %s: [%s]%s %+v %+v\n", infoText("Request"), t.Request.Method, t.Request.URL, *t.Request.Body, t.Request.Headers)
+	body := ""
+	if t.Request.Body != nil {
+		body = *t.Request.Body
+	}
+	fmt.Printf("  %s: [%s]%s %+v %+v\n", infoText("Request"), t.Request.Method, t.Request.URL, body, t.Request.Headers)
+	if a.LastResponse != nil {
+		fmt.Printf("  %s: %s\n", infoText("Timing"), a.LastResponse.Timing)
+	}
 	fmt.Printf("  %s: %+v\n", infoText("Store"), a.Store)
 }
 
@@ -38,6 +48,35 @@ func (a *Abdd) PrintExtractDataStep(t *Test) {
 	fmt.Printf("  %s: %+v\n", infoText("Extracted"), a.Store)
 }
 
+// PrintStepResults lists the outcome of each step under a multi-step test.
+func (a *Abdd) PrintStepResults(results []StepResult) {
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			fmt.Printf("       %s %s\n", infoText("-"), r.Name)
+		case r.Err != nil:
+			fmt.Printf("       %s %s\n", failureText("✗"), r.Name)
+		default:
+			fmt.Printf("       %s %s\n", successText("✓"), r.Name)
+		}
+	}
+}
+
+// PrintServiceLogs prints what each service logged while the test ran.
+func (a *Abdd) PrintServiceLogs() {
+	a.services.each(func(s *service) {
+		logs := s.logs.Since()
+		if strings.TrimSpace(logs) == "" {
+			return
+		}
+
+		fmt.Printf("\n  %s: %s\n", infoText("Service logs"), s.config.Name)
+		for _, line := range strings.Split(tail(logs, serviceLogTailLines), "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	})
+}
+
 func (a *Abdd) PrintFailureDetails(t *Test) {
 	fmt.Println(failureText("\n❯ Test Failure Details:"))
 
@@ -67,13 +106,20 @@ func (a *Abdd) PrintFailureDetails(t *Test) {
 		if t.Command.Directory != "" {
 			fmt.Printf("    %s: %s\n", infoText("Directory"), t.Command.Directory)
 		}
+
+		if a.LastCommand != nil {
+			fmt.Printf("    %s: %d\n", infoText("Exit code"), a.LastCommand.ExitCode)
+			fmt.Printf("    %s: %s\n", infoText("Stdout"), a.LastCommand.Stdout)
+			fmt.Printf("    %s: %s\n", infoText("Stderr"), a.LastCommand.Stderr)
+		}
 	}
 
-	if a.LastResponse != nil {
+	if a.LastResponse != nil && t.Request != nil {
 		fmt.Printf("  %s:\n", infoText("Response"))
 		if a.LastResponse.Code != nil {
 			fmt.Printf("    %s: %d\n", infoText("Status"), *a.LastResponse.Code)
 		}
+		fmt.Printf("    %s: %s\n", infoText("Timing"), a.LastResponse.Timing)
 
 		if a.LastResponse.Headers != nil {
 			fmt.Printf("    %s:\n", infoText("Headers"))
@@ -82,6 +128,13 @@ func (a *Abdd) PrintFailureDetails(t *Test) {
 			}
 		}
 
+		if len(a.LastResponse.Redirects) > 0 {
+			fmt.Printf("    %s:\n", infoText("Redirects"))
+			for _, r := range a.LastResponse.Redirects {
+				fmt.Printf("      %d %s %s -> %s\n", r.Status, r.Method, r.URL, r.Location)
+			}
+		}
+
 		if a.LastResponse.Body != nil {
 			fmt.Printf("    %s: %s\n", infoText("Body"), *a.LastResponse.Body)
 		}
@@ -95,8 +148,22 @@ func (a *Abdd) PrintFailureDetails(t *Test) {
 	}
 
 	fmt.Printf("\n  %s:\n", infoText("Expected"))
+
+	if t.Expect.ExitCode != nil {
+		fmt.Printf("    %s: %d\n", infoText("Exit code"), *t.Expect.ExitCode)
+	}
+
+	if t.Expect.Stdout != nil {
+		fmt.Printf("    %s:\n", infoText("Stdout"))
+		a.PrintTextExpect(t.Expect.Stdout)
+	}
+
+	if t.Expect.Stderr != nil {
+		fmt.Printf("    %s:\n", infoText("Stderr"))
+		a.PrintTextExpect(t.Expect.Stderr)
+	}
 	if t.Expect.Status != nil {
-		fmt.Printf("    %s: %d\n", infoText("Status"), *t.Expect.Status)
+		fmt.Printf("    %s: %s\n", infoText("Status"), t.Expect.Status)
 	}
 
 	if t.Expect.Headers != nil {
@@ -106,6 +173,67 @@ func (a *Abdd) PrintFailureDetails(t *Test) {
 		}
 	}
 
+	if t.Expect.MaxDuration != nil {
+		fmt.Printf("    %s: %s\n", infoText("Max duration"), *t.Expect.MaxDuration)
+	}
+
+	if t.Expect.Redirects != nil {
+		fmt.Printf("    %s:\n", infoText("Redirects"))
+		for _, r := range t.Expect.Redirects {
+			if r.Status != nil {
+				fmt.Printf("      %s: %d\n", infoText("Status"), *r.Status)
+			}
+			if r.URL != "" {
+				fmt.Printf("      %s: %s\n", infoText("URL"), r.URL)
+			}
+			if r.Location != "" {
+				fmt.Printf("      %s: %s\n", infoText("Location"), r.Location)
+			}
+		}
+	}
+
+	if t.Expect.Schema != nil {
+		fmt.Printf("    %s: %s\n", infoText("Schema"), jsonString(t.Expect.Schema))
+	}
+
+	if t.Expect.Snapshot != nil && t.Expect.Snapshot.Enabled {
+		fmt.Printf("    %s: %s\n", infoText("Snapshot"), SnapshotPath(t))
+	}
+
+	if t.Expect.Body != nil && a.LastResponse != nil {
+		if diffs, err := a.BodyDiff(t); err == nil && len(diffs) > 0 {
+			fmt.Printf("    %s:\n", infoText("Body diff"))
+			a.PrintDiff(diffs)
+		}
+	}
+
+	if t.Expect.Text != nil {
+		fmt.Printf("    %s:\n", infoText("Text"))
+		a.PrintTextExpect(t.Expect.Text)
+	}
+
+	if t.Expect.BodySize != nil {
+		fmt.Printf("    %s: %d\n", infoText("Body size"), *t.Expect.BodySize)
+	}
+
+	if t.Expect.BodySHA256 != "" {
+		fmt.Printf("    %s: %s\n", infoText("Body SHA-256"), t.Expect.BodySHA256)
+	}
+
+	if t.Expect.XML != nil {
+		fmt.Printf("    %s:\n", infoText("XML"))
+		for k, v := range t.Expect.XML {
+			fmt.Printf("      %s: %v\n", k, v)
+		}
+	}
+
+	if t.Expect.HTML != nil {
+		fmt.Printf("    %s:\n", infoText("HTML"))
+		for k, v := range t.Expect.HTML {
+			fmt.Printf("      %s: %v\n", k, v)
+		}
+	}
+
 	if t.Expect.Json != nil {
 		fmt.Printf("    %s:\n", infoText("JSON"))
 		for k, v := range t.Expect.Json {
@@ -113,5 +241,115 @@ func (a *Abdd) PrintFailureDetails(t *Test) {
 		}
 	}
 
+	a.PrintQuantifier("Each", t.Expect.Each)
+	a.PrintQuantifier("Any", t.Expect.Any)
+
+	for path, expect := range t.Expect.JWT {
+		fmt.Printf("    %s: %s\n", infoText("JWT"), path)
+		if expect.Verify {
+			fmt.Printf("      %s: true\n", infoText("Verify"))
+		}
+		if expect.Valid {
+			fmt.Printf("      %s: true\n", infoText("Valid"))
+		}
+		for k, v := range expect.Header {
+			fmt.Printf("      %s %s: %v\n", infoText("Header"), k, v)
+		}
+		for k, v := range expect.Claims {
+			fmt.Printf("      %s %s: %v\n", infoText("Claim"), k, v)
+		}
+	}
+
+	if len(t.Expect.Assert) > 0 {
+		fmt.Printf("    %s:\n", infoText("Assert"))
+		for _, expr := range t.Expect.Assert {
+			fmt.Printf("      %s\n", expr)
+		}
+	}
+
+	fmt.Println()
+}
+
+func (a *Abdd) PrintQuantifier(label string, quantifier map[string]map[string]any) {
+	if quantifier == nil {
+		return
+	}
+
+	fmt.Printf("    %s:\n", infoText(label))
+	for path, assertions := range quantifier {
+		fmt.Printf("      %s:\n", path)
+		for k, v := range assertions {
+			fmt.Printf("        %s: %v\n", k, v)
+		}
+	}
+}
+
+func (a *Abdd) PrintTextExpect(e *TextExpect) {
+	if e.Equals != nil {
+		fmt.Printf("      %s: %q\n", infoText("Equals"), *e.Equals)
+	}
+	if e.Contains != "" {
+		fmt.Printf("      %s: %q\n", infoText("Contains"), e.Contains)
+	}
+	if e.Matches != "" {
+		fmt.Printf("      %s: %s\n", infoText("Matches"), e.Matches)
+	}
+	if e.LineCount != nil {
+		fmt.Printf("      %s: %d\n", infoText("Line count"), *e.LineCount)
+	}
+}
+
+// PrintDiff prints each difference with the expected value in green and the actual value in red.
+func (a *Abdd) PrintDiff(diffs []Difference) {
+	for _, d := range diffs {
+		path := d.Path
+		if path == "" {
+			path = "@this"
+		}
+
+		fmt.Printf("      %s\n", infoText(path))
+		if d.HasExpected {
+			fmt.Printf("        %s\n", successText("- "+jsonString(d.Expected)))
+		}
+		if d.HasActual {
+			fmt.Printf("        %s\n", failureText("+ "+jsonString(d.Actual)))
+		}
+	}
+}
+
+func (a *Abdd) PrintCoverage(r *CoverageReport) {
+	fmt.Println(headerText("┌─────────────────────────────────┐"))
+	fmt.Println(headerText("          OpenAPI Coverage         "))
+
+	for _, op := range r.Operations {
+		mark := failureText("✗")
+		if op.Hits > 0 {
+			mark = successText("✓")
+		}
+		fmt.Printf("%s %s %s (%d)\n", mark, op.Method, op.Path, op.Hits)
+
+		for _, s := range op.Statuses {
+			status := s.Status
+			if !s.Documented {
+				status = failureText(status + " undocumented")
+			}
+			fmt.Printf("    %s: %d\n", status, s.Hits)
+		}
+	}
+
+	if len(r.Undocumented) > 0 {
+		fmt.Printf("\n%s:\n", infoText("Undocumented requests"))
+		for _, u := range r.Undocumented {
+			fmt.Printf("  %s %s -> %d (%d)\n", u.Method, u.Path, u.Status, u.Hits)
+		}
+	}
+
+	rate := 0.0
+	if r.Total > 0 {
+		rate = float64(r.Covered) / float64(r.Total) * 100
+	}
+	fmt.Printf("\nOperations covered: %d/%d (%.1f%%)\n", r.Covered, r.Total, rate)
+
 	fmt.Println()
+	fmt.Println(headerText("└─────────────────────────────────┘"))
 }
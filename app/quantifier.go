@@ -0,0 +1,100 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// validateQuantifiers applies the nested json assertions of expect.each to every
+// element of an array path, and those of expect.any to at least one element.
+func (a *Abdd) validateQuantifiers(t *Test) error {
+	if (t.Expect.Each == nil && t.Expect.Any == nil) || a.LastResponse.Body == nil {
+		return nil
+	}
+
+	for _, path := range sortedQuantifierPaths(t.Expect.Each) {
+		items, err := a.quantifierItems(path)
+		if err != nil {
+			return err
+		}
+
+		var failures []string
+		for i, item := range items {
+			if mismatch := elementMismatch(item, t.Expect.Each[path], t.Expect.Loose); mismatch != "" {
+				failures = append(failures, fmt.Sprintf("[%d] %s", i, mismatch))
+			}
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("%w: %d of %d items in %s failed: %s", ErrEachNotSatisfied, len(failures), len(items), path, strings.Join(failures, "; "))
+		}
+	}
+
+	for _, path := range sortedQuantifierPaths(t.Expect.Any) {
+		items, err := a.quantifierItems(path)
+		if err != nil {
+			return err
+		}
+
+		matched := false
+		for _, item := range items {
+			if elementMismatch(item, t.Expect.Any[path], t.Expect.Loose) == "" {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%w: none of the %d items in %s matched", ErrAnyNotSatisfied, len(items), path)
+		}
+	}
+
+	return nil
+}
+
+func (a *Abdd) quantifierItems(path string) ([]gjson.Result, error) {
+	value := gjson.Get(*a.LastResponse.Body, path)
+	if !value.Exists() {
+		return nil, fmt.Errorf("%w: expected %s to be present", ErrJsonPathNotFound, path)
+	}
+	if !value.IsArray() {
+		return nil, fmt.Errorf("%w: expected %s to be an array, got %s", ErrJsonPathNotEqual, path, value.Raw)
+	}
+	return value.Array(), nil
+}
+
+// elementMismatch returns the first failing assertion for an array element, or "".
+func elementMismatch(item gjson.Result, assertions map[string]any, loose bool) string {
+	for _, key := range sortedKeys(assertions) {
+		actual := item.Get(key)
+		if !actual.Exists() {
+			return fmt.Sprintf("expected %s to be present", key)
+		}
+		if mismatch := jsonMismatch(key, assertions[key], actual, loose); mismatch != "" {
+			return mismatch
+		}
+	}
+	return ""
+}
+
+func sortedQuantifierPaths(quantifier map[string]map[string]any) []string {
+	paths := make([]string, 0, len(quantifier))
+	for path := range quantifier {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (a *Abdd) replaceVariablesInQuantifier(quantifier map[string]map[string]any) map[string]map[string]any {
+	if quantifier == nil {
+		return nil
+	}
+
+	replaced := make(map[string]map[string]any, len(quantifier))
+	for path, assertions := range quantifier {
+		replaced[path] = a.replaceVariablesInValue(assertions).(map[string]any)
+	}
+	return replaced
+}
@@ -0,0 +1,109 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResponseQuantifiers(t *testing.T) {
+	body := `{"data": [
+		{"id": 1, "status": "active", "owner": {"name": "Ann"}},
+		{"id": 2, "status": "inactive", "owner": {"name": "Bob"}},
+		{"id": 3, "status": "active"}
+	], "meta": {"total": 3}}`
+
+	testCases := []struct {
+		name    string
+		expect  string
+		store   map[string]any
+		wantErr string
+	}{
+		{
+			name: "Failing indices are reported",
+			expect: `
+each:
+  data:
+    id: 1.0
+    status: active`,
+			wantErr: `each not satisfied: 2 of 3 items in data failed: [1] expected id to be 1, got 2; [2] expected id to be 1, got 3`,
+		},
+		{
+			name: "Every item has a status",
+			expect: `
+each:
+  data:
+    status: active`,
+			wantErr: `each not satisfied: 1 of 3 items in data failed: [1] expected status to be "active", got "inactive"`,
+		},
+		{
+			name: "Missing nested path",
+			expect: `
+each:
+  data:
+    owner.name: Ann`,
+			wantErr: `[1] expected owner.name to be "Ann", got "Bob"; [2] expected owner.name to be present`,
+		},
+		{
+			name: "Some item matches a variable",
+			expect: `
+any:
+  data:
+    id: ${businessId}
+    status: inactive`,
+			store: map[string]any{"businessId": uint64(2)},
+		},
+		{
+			name: "No item matches",
+			expect: `
+any:
+  data:
+    id: 4`,
+			wantErr: "any not satisfied: none of the 3 items in data matched",
+		},
+		{
+			name: "Path is not an array",
+			expect: `
+each:
+  meta:
+    total: 3`,
+			wantErr: `json path not equal: expected meta to be an array, got {"total": 3}`,
+		},
+		{
+			name: "Path does not exist",
+			expect: `
+any:
+  items:
+    id: 1`,
+			wantErr: "json path not found: expected items to be present",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var expect app.TestExpect
+			require.NoError(t, yaml.Unmarshal([]byte(tc.expect), &expect))
+
+			a := &app.Abdd{
+				Store:        map[string]any{},
+				LastResponse: &app.LastResponse{Body: toPointer(body)},
+			}
+			for k, v := range tc.store {
+				a.Store[k] = v
+			}
+
+			test := &app.Test{Request: &app.TestRequest{}, Expect: expect}
+			require.NoError(t, a.ReplaceVariables(test))
+
+			err := a.ValidateResponse(test)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -21,7 +21,47 @@ func (a *Abdd) MakeRequest(t *Test) error {
 		req.Header.Set(key, value)
 	}
 
-	resp, err := a.Client.Do(req)
+	if t.Request.ClearSession {
+		a.ClearSession(t.Request.Session)
+	}
+
+	client := *a.Client
+	jar, err := a.sessionJar(t.Request.Session)
+	if err != nil {
+		return err
+	}
+	if jar != nil {
+		client.Jar = jar
+	}
+
+	checkRedirect := client.CheckRedirect
+	if t.Request.FollowRedirects != nil {
+		checkRedirect = t.Request.FollowRedirects.CheckRedirect
+	}
+	if checkRedirect == nil {
+		checkRedirect = RedirectPolicy{Follow: true}.CheckRedirect
+	}
+
+	var redirects []Redirect
+	client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if err := checkRedirect(next, via); err != nil {
+			return err
+		}
+
+		prev := via[len(via)-1]
+		redirects = append(redirects, Redirect{
+			Method:   prev.Method,
+			URL:      prev.URL.String(),
+			Status:   next.Response.StatusCode,
+			Location: next.Response.Header.Get("Location"),
+		})
+		return nil
+	}
+
+	ctx, timing := withTiming(req.Context())
+	req = req.WithContext(ctx)
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to make request: %w", err)
 	}
@@ -31,6 +71,7 @@ func (a *Abdd) MakeRequest(t *Test) error {
 	if err != nil {
 		return err
 	}
+	elapsed := timing.finish()
 
 	respHeaders := map[string]string{}
 	for key, values := range resp.Header {
@@ -39,13 +80,32 @@ func (a *Abdd) MakeRequest(t *Test) error {
 		}
 	}
 
+	// The final request differs from the original one when redirects were followed.
+	finalReq := req
+	if resp.Request != nil {
+		finalReq = resp.Request
+	}
+
 	respBody := string(bodyBytes)
 	lr := LastResponse{
-		Headers: respHeaders,
-		Body:    &respBody,
-		Code:    &resp.StatusCode,
+		Method:     finalReq.Method,
+		Path:       finalReq.URL.Path,
+		Headers:    respHeaders,
+		RawHeaders: resp.Header.Clone(),
+		Body:       &respBody,
+		Code:       &resp.StatusCode,
+		Redirects:  redirects,
+		Timing:     elapsed,
+	}
+	if a.OpenAPI != nil {
+		lr.Operation = a.OpenAPI.Match(lr.Method, lr.Path)
+		a.recordCoverage(&lr)
 	}
 	a.LastResponse = &lr
 
+	if t.SaveResponseAs != "" {
+		a.saveResponse(t.SaveResponseAs)
+	}
+
 	return nil
 }
@@ -262,6 +262,7 @@ func TestMakeRequest(t *testing.T) {
 				assert.Equal(t, 200, *a.LastResponse.Code)
 				assert.Equal(t, "session=123, user=john", a.LastResponse.Headers["Set-Cookie"])
 				assert.Equal(t, "no-cache, no-store", a.LastResponse.Headers["Cache-Control"])
+				assert.Equal(t, []string{"session=123", "user=john"}, a.LastResponse.RawHeaders.Values("set-cookie"))
 			},
 		},
 	}
@@ -281,3 +282,77 @@ func TestMakeRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeRequestRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.Redirect(w, r, "/session", http.StatusFound)
+		case "/session":
+			http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+		default:
+			w.Write([]byte("dashboard"))
+		}
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name          string
+		global        *app.RedirectPolicy
+		request       *app.RedirectPolicy
+		wantCode      int
+		wantLocations []string
+	}{
+		{
+			name:          "Follows redirects by default",
+			wantCode:      http.StatusOK,
+			wantLocations: []string{"/session", "/dashboard"},
+		},
+		{
+			name:     "Request disables redirects",
+			request:  &app.RedirectPolicy{Follow: false},
+			wantCode: http.StatusFound,
+		},
+		{
+			name:          "Request limits redirects",
+			request:       &app.RedirectPolicy{Follow: true, Max: 1},
+			wantCode:      http.StatusSeeOther,
+			wantLocations: []string{"/session"},
+		},
+		{
+			name:          "Request overrides global policy",
+			global:        &app.RedirectPolicy{Follow: false},
+			request:       &app.RedirectPolicy{Follow: true},
+			wantCode:      http.StatusOK,
+			wantLocations: []string{"/session", "/dashboard"},
+		},
+		{
+			name:     "Global policy applies without request policy",
+			global:   &app.RedirectPolicy{Follow: false},
+			wantCode: http.StatusFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := app.NewClient(app.Config{FollowRedirects: tc.global})
+			assert.NoError(t, err)
+
+			a := &app.Abdd{
+				Global: app.Global{Config: app.Config{BaseURL: server.URL}},
+				Client: client,
+			}
+			test := &app.Test{Request: &app.TestRequest{Method: "GET", URL: "/login", FollowRedirects: tc.request}}
+
+			err = a.MakeRequest(test)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantCode, *a.LastResponse.Code)
+
+			var locations []string
+			for _, r := range a.LastResponse.Redirects {
+				locations = append(locations, r.Location)
+			}
+			assert.Equal(t, tc.wantLocations, locations)
+		})
+	}
+}
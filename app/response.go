@@ -1,6 +1,7 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/tidwall/gjson"
@@ -12,50 +13,158 @@ func (a *Abdd) ValidateResponse(t *Test) error {
 	}
 
 	if t.Expect.Status != nil && a.LastResponse.Code != nil {
-		if *a.LastResponse.Code != *t.Expect.Status {
-			return fmt.Errorf("%w: expected %d, got %d", ErrUnexpectedStatusCode, *t.Expect.Status, *a.LastResponse.Code)
+		if !t.Expect.Status.Matches(*a.LastResponse.Code) {
+			return fmt.Errorf("%w: expected %s, got %d", ErrUnexpectedStatusCode, t.Expect.Status, *a.LastResponse.Code)
 		}
 	}
 
-	if t.Expect.Headers != nil {
-		for key, expectedValue := range t.Expect.Headers {
-			actualValue, exists := a.LastResponse.Headers[key]
-			if !exists {
-				return fmt.Errorf("%w: expected %s to be present", ErrHeaderNotFound, key)
+	if err := a.validateTiming(t); err != nil {
+		return err
+	}
+
+	for key, expected := range t.Expect.Headers {
+		if err := a.validateHeader(key, expected); err != nil {
+			return err
+		}
+	}
+
+	if t.Expect.Redirects != nil {
+		if len(t.Expect.Redirects) != len(a.LastResponse.Redirects) {
+			return fmt.Errorf("%w: expected %d redirects, got %d", ErrRedirectNotEqual, len(t.Expect.Redirects), len(a.LastResponse.Redirects))
+		}
+
+		for i, expected := range t.Expect.Redirects {
+			actual := a.LastResponse.Redirects[i]
+			if expected.Status != nil && *expected.Status != actual.Status {
+				return fmt.Errorf("%w: expected redirect %d status to be %d, got %d", ErrRedirectNotEqual, i, *expected.Status, actual.Status)
+			}
+			if expected.URL != "" && expected.URL != actual.URL {
+				return fmt.Errorf("%w: expected redirect %d url to be %s, got %s", ErrRedirectNotEqual, i, expected.URL, actual.URL)
 			}
-			if actualValue != expectedValue {
-				return fmt.Errorf("%w: expected header %s to be %s, got %s", ErrHeaderNotEqual, key, expectedValue, actualValue)
+			if expected.Location != "" && expected.Location != actual.Location {
+				return fmt.Errorf("%w: expected redirect %d location to be %s, got %s", ErrRedirectNotEqual, i, expected.Location, actual.Location)
 			}
 		}
 	}
 
+	if a.OpenAPI != nil {
+		if err := a.validateOpenAPI(t); err != nil {
+			return err
+		}
+	}
+
+	if t.Expect.Schema != nil {
+		if err := a.validateSchema(t); err != nil {
+			return err
+		}
+	}
+
+	if t.Expect.Body != nil {
+		if err := a.validateBody(t); err != nil {
+			return err
+		}
+	}
+
+	if t.Expect.Snapshot != nil && t.Expect.Snapshot.Enabled {
+		if err := a.validateSnapshot(t); err != nil {
+			return err
+		}
+	}
+
+	if err := a.validateBodyBytes(t); err != nil {
+		return err
+	}
+
+	if t.Expect.XML != nil {
+		if err := a.validateMarkup(t.Expect.XML, formatXML); err != nil {
+			return err
+		}
+	}
+
+	if t.Expect.HTML != nil {
+		if err := a.validateMarkup(t.Expect.HTML, formatHTML); err != nil {
+			return err
+		}
+	}
+
 	if t.Expect.Json != nil && a.LastResponse.Body != nil {
-		for key, expectedValue := range t.Expect.Json {
-			actualValue := gjson.Get(*a.LastResponse.Body, key)
-			if !actualValue.Exists() {
-				return fmt.Errorf("%w: expected %s to be present", ErrJsonPathNotFound, key)
-			}
+		if err := validateJSON(*a.LastResponse.Body, t.Expect.Json, t.Expect.Loose); err != nil {
+			return err
+		}
+	}
 
-			isActualNull := actualValue.Type == gjson.Null
-			// Hack to check if the expected value is nil or "<nil>"
-			isExpectedNull := expectedValue == nil || expectedValue == "<nil>"
-			if isActualNull && isExpectedNull {
-				continue
-			}
+	if err := a.validateQuantifiers(t); err != nil {
+		return err
+	}
 
-			if isActualNull {
-				return fmt.Errorf("%w: expected %s to be %v, got null", ErrJsonPathNotEqual, key, expectedValue)
-			}
+	if err := a.validateJWT(t); err != nil {
+		return err
+	}
 
-			if isExpectedNull {
-				return fmt.Errorf("%w: expected %s to be null, got %s", ErrJsonPathNotEqual, key, actualValue.String())
-			}
+	if err := a.validateAssertions(t); err != nil {
+		return err
+	}
 
-			if actualValue.String() != fmt.Sprintf("%v", expectedValue) {
-				return fmt.Errorf("%w: expected %s to be %v, got %v", ErrJsonPathNotEqual, key, expectedValue, actualValue.String())
-			}
+	return nil
+}
+
+// validateJSON checks each gjson path in expectations against the document.
+func validateJSON(document string, expectations map[string]any, loose bool) error {
+	for key, expectedValue := range expectations {
+		actualValue := gjson.Get(document, key)
+		if !actualValue.Exists() {
+			return fmt.Errorf("%w: expected %s to be present", ErrJsonPathNotFound, key)
+		}
+
+		if err := compareJSONValue(key, expectedValue, actualValue, loose); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
+// compareJSONValue compares with JSON semantics, so 1 equals 1.0 but not "1".
+// In loose mode both sides are compared as strings, except for null.
+func compareJSONValue(key string, expected any, actual gjson.Result, loose bool) error {
+	if mismatch := jsonMismatch(key, expected, actual, loose); mismatch != "" {
+		return fmt.Errorf("%w: %s", ErrJsonPathNotEqual, mismatch)
+	}
 	return nil
 }
+
+// jsonMismatch describes how actual differs from expected, or returns "" when they are equal.
+func jsonMismatch(key string, expected any, actual gjson.Result, loose bool) string {
+	isActualNull := actual.Type == gjson.Null
+	isExpectedNull := expected == nil
+	if isActualNull && isExpectedNull {
+		return ""
+	}
+
+	if loose {
+		if isActualNull {
+			return fmt.Sprintf("expected %s to be %v, got null", key, expected)
+		}
+		if isExpectedNull {
+			return fmt.Sprintf("expected %s to be null, got %s", key, actual.String())
+		}
+		if actual.String() != fmt.Sprintf("%v", expected) {
+			return fmt.Sprintf("expected %s to be %v, got %v", key, expected, actual.String())
+		}
+		return ""
+	}
+
+	normalized, err := normalizeJSON(expected)
+	if err != nil {
+		return fmt.Sprintf("invalid expected value for %s: %v", key, err)
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(actual.Raw), &value); err != nil {
+		return fmt.Sprintf("%s is not valid json", key)
+	}
+
+	if !jsonEqual(normalized, value) {
+		return fmt.Sprintf("expected %s to be %s, got %s", key, jsonString(normalized), jsonString(value))
+	}
+	return ""
+}
@@ -22,9 +22,9 @@ func TestValidateResponse(t *testing.T) {
 			name: "Valid response with code, headers, and json body",
 			test: app.Test{
 				Expect: app.TestExpect{
-					Status: toPointer(200),
-					Headers: map[string]string{
-						"Content-Type": "application/json",
+					Status: app.NewStatusExpect(200),
+					Headers: map[string]app.HeaderExpect{
+						"Content-Type": {Equals: "application/json"},
 					},
 					Json: map[string]any{
 						"key": "value",
@@ -43,7 +43,7 @@ func TestValidateResponse(t *testing.T) {
 			name: "Unexpected status code",
 			test: app.Test{
 				Expect: app.TestExpect{
-					Status: toPointer(200),
+					Status: app.NewStatusExpect(200),
 				},
 			},
 			lastResponse: &app.LastResponse{
@@ -55,8 +55,8 @@ func TestValidateResponse(t *testing.T) {
 			name: "Header not found",
 			test: app.Test{
 				Expect: app.TestExpect{
-					Headers: map[string]string{
-						"Content-Type": "application/json",
+					Headers: map[string]app.HeaderExpect{
+						"Content-Type": {Equals: "application/json"},
 					},
 				},
 			},
@@ -67,8 +67,8 @@ func TestValidateResponse(t *testing.T) {
 			name: "Header mismatch",
 			test: app.Test{
 				Expect: app.TestExpect{
-					Headers: map[string]string{
-						"Content-Type": "application/json",
+					Headers: map[string]app.HeaderExpect{
+						"Content-Type": {Equals: "application/json"},
 					},
 				},
 			},
@@ -79,6 +79,49 @@ func TestValidateResponse(t *testing.T) {
 			},
 			expectedErr: fmt.Errorf("%w: expected header %s to be %s, got %s", app.ErrHeaderNotEqual, "Content-Type", "application/json", "text/html"),
 		},
+		{
+			name: "Redirect chain matches",
+			test: app.Test{
+				Expect: app.TestExpect{
+					Redirects: []app.ExpectRedirect{
+						{Status: toPointer(302), Location: "/dashboard"},
+					},
+				},
+			},
+			lastResponse: &app.LastResponse{
+				Redirects: []app.Redirect{
+					{Method: "GET", URL: "https://example.com/login", Status: 302, Location: "/dashboard"},
+				},
+			},
+		},
+		{
+			name: "Redirect count mismatch",
+			test: app.Test{
+				Expect: app.TestExpect{
+					Redirects: []app.ExpectRedirect{
+						{Status: toPointer(302)},
+					},
+				},
+			},
+			lastResponse: &app.LastResponse{},
+			expectedErr:  fmt.Errorf("%w: expected %d redirects, got %d", app.ErrRedirectNotEqual, 1, 0),
+		},
+		{
+			name: "Redirect location mismatch",
+			test: app.Test{
+				Expect: app.TestExpect{
+					Redirects: []app.ExpectRedirect{
+						{Location: "/dashboard"},
+					},
+				},
+			},
+			lastResponse: &app.LastResponse{
+				Redirects: []app.Redirect{
+					{Method: "GET", URL: "https://example.com/login", Status: 302, Location: "/login"},
+				},
+			},
+			expectedErr: fmt.Errorf("%w: expected redirect %d location to be %s, got %s", app.ErrRedirectNotEqual, 0, "/dashboard", "/login"),
+		},
 		{
 			name: "JSON path not found",
 			test: app.Test{
@@ -105,7 +148,7 @@ func TestValidateResponse(t *testing.T) {
 			lastResponse: &app.LastResponse{
 				Body: toPointer(`{"key": "other_value"}`),
 			},
-			expectedErr: fmt.Errorf("%w: expected %s to be %v, got %v", app.ErrJsonPathNotEqual, "key", "value", "other_value"),
+			expectedErr: fmt.Errorf("%w: expected %s to be %q, got %q", app.ErrJsonPathNotEqual, "key", "value", "other_value"),
 		},
 		{
 			name: "JSON value is null when not expected",
@@ -119,7 +162,7 @@ func TestValidateResponse(t *testing.T) {
 			lastResponse: &app.LastResponse{
 				Body: toPointer(`{"key": null}`),
 			},
-			expectedErr: fmt.Errorf("%w: expected %s to be %s, got null", app.ErrJsonPathNotEqual, "key", "value"),
+			expectedErr: fmt.Errorf("%w: expected %s to be %q, got null", app.ErrJsonPathNotEqual, "key", "value"),
 		},
 		{
 			name: "JSON value is not null when expected",
@@ -133,7 +176,7 @@ func TestValidateResponse(t *testing.T) {
 			lastResponse: &app.LastResponse{
 				Body: toPointer(`{"key": "value"}`),
 			},
-			expectedErr: fmt.Errorf("%w: expected %s to be null, got %v", app.ErrJsonPathNotEqual, "key", "value"),
+			expectedErr: fmt.Errorf("%w: expected %s to be null, got %q", app.ErrJsonPathNotEqual, "key", "value"),
 		},
 		{
 			name: "JSON value is null when expected",
@@ -149,6 +192,50 @@ func TestValidateResponse(t *testing.T) {
 			},
 			expectedErr: fmt.Errorf("%w: expected %s to be %v, got null", app.ErrJsonPathNotEqual, "key", nil),
 		},
+		{
+			name: "JSON numbers compare by value",
+			test: app.Test{
+				Expect: app.TestExpect{
+					Json: map[string]any{
+						"int":   uint64(1),
+						"float": 2.0,
+						"tags":  []any{"a", "b"},
+					},
+				},
+			},
+			lastResponse: &app.LastResponse{
+				Body: toPointer(`{"int": 1.0, "float": 2, "tags": ["a", "b"]}`),
+			},
+		},
+		{
+			name: "JSON number does not equal string",
+			test: app.Test{
+				Expect: app.TestExpect{
+					Json: map[string]any{
+						"id": uint64(1),
+					},
+				},
+			},
+			lastResponse: &app.LastResponse{
+				Body: toPointer(`{"id": "1"}`),
+			},
+			expectedErr: fmt.Errorf("%w: expected %s to be %d, got %q", app.ErrJsonPathNotEqual, "id", 1, "1"),
+		},
+		{
+			name: "Loose mode coerces to strings",
+			test: app.Test{
+				Expect: app.TestExpect{
+					Loose: true,
+					Json: map[string]any{
+						"id":     uint64(1),
+						"active": "true",
+					},
+				},
+			},
+			lastResponse: &app.LastResponse{
+				Body: toPointer(`{"id": "1", "active": true}`),
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+const responsesPrefix = "responses."
+
+// saveResponse keeps the last response under name for later ${responses.name...} references.
+func (a *Abdd) saveResponse(name string) {
+	if a.Responses == nil {
+		a.Responses = make(map[string]*LastResponse)
+	}
+	a.Responses[name] = a.LastResponse
+}
+
+// lookupVariable resolves a placeholder key from the store or from a saved
+// response, e.g. responses.login.status, responses.login.headers.Location or
+// responses.login.body.data.id.
+func (a *Abdd) lookupVariable(key string) (any, bool) {
+	if val, ok := a.Store[key]; ok {
+		return val, true
+	}
+
+	if !strings.HasPrefix(key, responsesPrefix) {
+		return nil, false
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(key, responsesPrefix), ".", 3)
+	lr, ok := a.Responses[parts[0]]
+	if !ok || lr == nil || len(parts) < 2 {
+		return nil, false
+	}
+
+	rest := ""
+	if len(parts) == 3 {
+		rest = parts[2]
+	}
+
+	switch parts[1] {
+	case "status":
+		if lr.Code == nil || rest != "" {
+			return nil, false
+		}
+		return *lr.Code, true
+	case "headers":
+		if _, ok := lr.headerValues(rest); rest == "" || !ok {
+			return nil, false
+		}
+		return lr.header(rest), true
+	case "body":
+		if lr.Body == nil {
+			return nil, false
+		}
+		value := gjson.Parse(*lr.Body)
+		if rest != "" {
+			value = value.Get(rest)
+		} else if !gjson.Valid(*lr.Body) {
+			return *lr.Body, true
+		}
+		if !value.Exists() {
+			return nil, false
+		}
+		stored, err := storeValue(value)
+		if err != nil {
+			return nil, false
+		}
+		return stored, true
+	}
+	return nil, false
+}
+
+// responsesEnv exposes saved responses to assertion expressions.
+func (a *Abdd) responsesEnv() map[string]any {
+	env := make(map[string]any, len(a.Responses))
+	for name, lr := range a.Responses {
+		headers := map[string]any{}
+		for k, v := range lr.Headers {
+			headers[k] = v
+		}
+
+		response := map[string]any{"headers": headers}
+		if lr.Code != nil {
+			response["status"] = *lr.Code
+		}
+		if value, ok := a.lookupVariable(responsesPrefix + name + ".body"); ok {
+			response["body"] = value
+		}
+		env[name] = response
+	}
+	return env
+}
@@ -0,0 +1,64 @@
+package app_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedResponses(t *testing.T) {
+	var resource string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			body, _ := io.ReadAll(r.Body)
+			resource = string(body)
+			w.Header().Set("Location", "/items/7")
+			w.WriteHeader(http.StatusCreated)
+		}
+		_, _ = w.Write([]byte(resource))
+	}))
+	defer server.Close()
+
+	a := &app.Abdd{
+		Global: app.Global{Config: app.Config{BaseURL: server.URL}},
+		Store:  map[string]any{},
+		Client: server.Client(),
+	}
+
+	put := &app.Test{
+		Request:        &app.TestRequest{Method: "PUT", URL: "/items/7", Body: toPointer(`{"id": 7, "name": "Widget"}`)},
+		SaveResponseAs: "update",
+	}
+	require.NoError(t, a.ReplaceVariables(put))
+	require.NoError(t, a.MakeRequest(put))
+
+	get := &app.Test{
+		Request: &app.TestRequest{Method: "GET", URL: "${responses.update.headers.location}?status=${responses.update.status}"},
+		Expect: app.TestExpect{
+			Body: &app.BodyExpect{Equals: "${responses.update.body}"},
+			Json: map[string]any{
+				"id":   "${responses.update.body.id}",
+				"name": "${responses.update.body.name}",
+			},
+			Assert: []string{"body == responses.update.body && responses.update.status == 201"},
+		},
+	}
+	require.NoError(t, a.ReplaceVariables(get))
+	assert.Equal(t, "/items/7?status=201", get.Request.URL)
+	assert.Equal(t, json.Number("7"), get.Expect.Json["id"])
+
+	require.NoError(t, a.MakeRequest(get))
+	assert.NoError(t, a.ValidateResponse(get))
+	assert.Equal(t, 201, *a.Responses["update"].Code)
+
+	unknown := &app.Test{Request: &app.TestRequest{URL: "/${responses.missing.status}/${responses.update.cookies}"}}
+	require.NoError(t, a.ReplaceVariables(unknown))
+	assert.Equal(t, "/${responses.missing.status}/${responses.update.cookies}", unknown.Request.URL)
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+)
+
+// StepResult is the outcome of one step of a multi-step test.
+type StepResult struct {
+	Name    string
+	Err     error
+	Skipped bool
+}
+
+// StepError reports the step that failed a multi-step test.
+type StepError struct {
+	Index int
+	Step  *Test
+	Err   error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %d (%s): %v", e.Index+1, e.Step.Name, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// RunTest runs a test, or each of its steps in order while sharing the store,
+// together with the test's own hooks. A multi-step test stops at the first
+// failing step and returns a *StepError.
+func (a *Abdd) RunTest(t *Test) ([]StepResult, error) {
+	var results []StepResult
+	err := a.withAllHooks(t.Hooks, t.File, func() error {
+		var err error
+		results, err = a.runTest(t)
+		return err
+	})
+	return results, err
+}
+
+func (a *Abdd) runTest(t *Test) ([]StepResult, error) {
+	if len(t.Steps) == 0 {
+		return nil, a.withEachHooks(t.Hooks, t.File, func() error {
+			return a.runPipeline(t)
+		})
+	}
+
+	if err := a.GenerateFakeData(t); err != nil {
+		return nil, err
+	}
+
+	// Steps are copied so variable replacement leaves the loaded test untouched.
+	steps := append([]Test(nil), t.Steps...)
+	results := make([]StepResult, len(steps))
+
+	var failed error
+	for i := range steps {
+		step := &steps[i]
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step %d", i+1)
+		}
+		if step.File == "" {
+			step.File = t.File
+		}
+		step.Parent = t
+		results[i].Name = step.Name
+
+		if failed != nil {
+			results[i].Skipped = true
+			continue
+		}
+
+		if a.Global.Config.Verbose {
+			a.PrintStartTest(step)
+		}
+
+		err := a.withEachHooks(t.Hooks, t.File, func() error {
+			_, err := a.RunTest(step)
+			return err
+		})
+		if err != nil {
+			results[i].Err = err
+			failed = &StepError{Index: i, Step: step, Err: err}
+		}
+	}
+
+	return results, failed
+}
+
+// runPipeline runs the steps of a single request or command.
+func (a *Abdd) runPipeline(t *Test) error {
+	err := a.GenerateFakeData(t)
+	if err == nil {
+		if a.Global.Config.Verbose {
+			a.PrintGenerateFakeDataStep(t)
+		}
+		err = a.ReplaceVariables(t)
+	}
+
+	if err == nil {
+		if a.Global.Config.Verbose {
+			a.PrintReplaceVariablesStep(t)
+		}
+		err = a.ExecuteCommand(t)
+	}
+
+	if err == nil {
+		if a.Global.Config.Verbose {
+			a.PrintExecuteCommandStep(t)
+		}
+		err = a.ValidateCommand(t)
+	}
+
+	// Command-only tests have no response to validate.
+	if err == nil && t.Request != nil {
+		err = a.MakeRequest(t)
+		if err == nil {
+			if a.Global.Config.Verbose {
+				a.PrintMakeRequestStep(t)
+			}
+			err = a.ValidateResponse(t)
+		}
+		if err == nil && a.Global.Config.Verbose {
+			a.PrintValidateResponseStep(t)
+		}
+	}
+
+	if err == nil {
+		err = a.ExtractData(t)
+	}
+
+	if err == nil {
+		if a.Global.Config.Verbose {
+			a.PrintExtractDataStep(t)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,125 @@
+package app_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestSteps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 7}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/items/7":
+			_, _ = w.Write([]byte(`{"id": 7, "name": "Widget"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	newAbdd := func() *app.Abdd {
+		return &app.Abdd{
+			Global: app.Global{Config: app.Config{BaseURL: server.URL}},
+			Store:  map[string]any{},
+			Client: server.Client(),
+		}
+	}
+
+	t.Run("Steps share variables", func(t *testing.T) {
+		var test app.Test
+		require.NoError(t, yaml.Unmarshal([]byte(`
+name: CRUD flow
+steps:
+  - name: create
+    request: {method: POST, url: /items}
+    expect: {status: 201}
+    extract:
+      - {path: id, as: itemId}
+  - request: {method: GET, url: "/items/${itemId}"}
+    expect:
+      status: 200
+      json: {name: Widget}
+`), &test))
+
+		a := newAbdd()
+		results, err := a.RunTest(&test)
+		require.NoError(t, err)
+		assert.Equal(t, []app.StepResult{{Name: "create"}, {Name: "step 2"}}, results)
+		assert.Equal(t, "/items/${itemId}", test.Steps[1].Request.URL)
+	})
+
+	t.Run("First failing step fails the test", func(t *testing.T) {
+		var test app.Test
+		require.NoError(t, yaml.Unmarshal([]byte(`
+name: Broken flow
+steps:
+  - name: create
+    request: {method: POST, url: /items}
+    expect: {status: 201}
+  - name: read missing
+    request: {method: GET, url: /items/8}
+    expect: {status: 200}
+  - name: never runs
+    request: {method: GET, url: /items/7}
+`), &test))
+
+		a := newAbdd()
+		results, err := a.RunTest(&test)
+		require.Error(t, err)
+
+		var stepErr *app.StepError
+		require.True(t, errors.As(err, &stepErr))
+		assert.Equal(t, 1, stepErr.Index)
+		assert.Equal(t, "read missing", stepErr.Step.Name)
+		assert.ErrorIs(t, err, app.ErrUnexpectedStatusCode)
+		assert.Contains(t, err.Error(), "step 2 (read missing): unexpected status code")
+
+		require.Len(t, results, 3)
+		assert.NoError(t, results[0].Err)
+		assert.Error(t, results[1].Err)
+		assert.True(t, results[2].Skipped)
+	})
+}
+
+func TestLoadTestsRejectsFieldsBesideSteps(t *testing.T) {
+	testCases := []struct {
+		field string
+		yaml  string
+	}{
+		{field: "request", yaml: "request: {method: GET, url: /}"},
+		{field: "command", yaml: "command: {command: echo}"},
+		{field: "expect", yaml: "expect: {status: 200}"},
+		{field: "extract", yaml: "extract: [{path: id, as: id}]"},
+		{field: "save_response_as", yaml: "save_response_as: created"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.field, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "flow.yaml"), []byte(`
+tests:
+  - name: mixed
+    `+tc.yaml+`
+    steps:
+      - request: {method: GET, url: /}
+`), 0o644))
+
+			a := &app.Abdd{}
+			err := a.LoadTests([]string{dir}, "")
+			assert.ErrorContains(t, err, "test 'mixed'")
+			assert.ErrorContains(t, err, "cannot have both steps and "+tc.field)
+		})
+	}
+}
@@ -0,0 +1,644 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SchemaError is a single JSON Schema violation. InstancePath points into the
+// validated document and KeywordPath into the schema, both as JSON pointers.
+type SchemaError struct {
+	InstancePath string
+	KeywordPath  string
+	Message      string
+}
+
+func (e SchemaError) Error() string {
+	instance := e.InstancePath
+	if instance == "" {
+		instance = "/"
+	}
+	return fmt.Sprintf("%s: %s (%s)", instance, e.Message, e.KeywordPath)
+}
+
+// ValidateSchema validates the instance against a JSON Schema (draft 2020-12).
+// Both values are expected in their encoding/json decoded form. Local $ref
+// pointers and $anchor names are resolved against root, which is usually the
+// schema itself. Keywords the validator does not implement are reported
+// instead of being ignored.
+func ValidateSchema(root, schema, instance any) []SchemaError {
+	v := &schemaValidator{root: root, run: &schemaRun{refs: map[string]bool{}}}
+	v.validate(schema, instance, "", "")
+	if len(v.run.unsupported) > 0 {
+		return v.run.unsupported
+	}
+	return v.errors
+}
+
+// unsupportedKeywords change what a schema accepts but are not implemented,
+// so validating with them would silently pass invalid bodies.
+var unsupportedKeywords = []string{"unevaluatedProperties", "unevaluatedItems", "$dynamicRef", "$recursiveRef"}
+
+// normalizeJSON converts a decoded YAML value into the types encoding/json produces.
+func normalizeJSON(value any) (any, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized any
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// loadSchema returns the schema for the test, reading it from a file relative
+// to the test file when the expectation is a path.
+func (a *Abdd) loadSchema(t *Test) (any, error) {
+	path, ok := t.Expect.Schema.(string)
+	if !ok {
+		return normalizeJSON(t.Expect.Schema)
+	}
+
+	if !filepath.IsAbs(path) && t.File != "" {
+		path = filepath.Join(filepath.Dir(t.File), path)
+	}
+
+	return loadDocument(path)
+}
+
+// loadDocument reads a JSON or YAML document from disk in its encoding/json form.
+func loadDocument(path string) (any, error) {
+	f, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var doc any
+	if err := yaml.Unmarshal(f, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	return normalizeJSON(doc)
+}
+
+func (a *Abdd) validateSchema(t *Test) error {
+	schema, err := a.loadSchema(t)
+	if err != nil {
+		return fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	var body any
+	if a.LastResponse.Body == nil || json.Unmarshal([]byte(*a.LastResponse.Body), &body) != nil {
+		return fmt.Errorf("%w: response body is not valid json", ErrSchemaMismatch)
+	}
+
+	return schemaErrors(ValidateSchema(schema, schema, body))
+}
+
+func schemaErrors(errs []SchemaError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Error()
+	}
+	return fmt.Errorf("%w: %s", ErrSchemaMismatch, strings.Join(messages, "; "))
+}
+
+type schemaValidator struct {
+	root   any
+	errors []SchemaError
+	run    *schemaRun
+}
+
+// schemaRun is shared by a validator and the ones it creates for applicators.
+type schemaRun struct {
+	// refs holds the $ref and instance path pairs being validated, to stop
+	// references that loop back without moving into the instance.
+	refs        map[string]bool
+	unsupported []SchemaError
+}
+
+func (r *schemaRun) unsupportedKeyword(kPath, keyword string) {
+	for _, e := range r.unsupported {
+		if e.KeywordPath == kPath {
+			return
+		}
+	}
+	r.unsupported = append(r.unsupported, SchemaError{KeywordPath: kPath, Message: "unsupported keyword " + keyword})
+}
+
+func (v *schemaValidator) fail(iPath, kPath, format string, args ...any) {
+	v.errors = append(v.errors, SchemaError{
+		InstancePath: iPath,
+		KeywordPath:  kPath,
+		Message:      fmt.Sprintf(format, args...),
+	})
+}
+
+// isValid validates without recording errors, for applicators such as anyOf and not.
+func (v *schemaValidator) isValid(schema, instance any, iPath, kPath string) bool {
+	sub := &schemaValidator{root: v.root, run: v.run}
+	sub.validate(schema, instance, iPath, kPath)
+	return len(sub.errors) == 0
+}
+
+func (v *schemaValidator) validate(schema, instance any, iPath, kPath string) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.fail(iPath, kPath, "no value is allowed")
+		}
+		return
+	case map[string]any:
+		v.validateObjectSchema(s, instance, iPath, kPath)
+	}
+}
+
+func (v *schemaValidator) validateObjectSchema(s map[string]any, instance any, iPath, kPath string) {
+	for _, keyword := range unsupportedKeywords {
+		if _, ok := s[keyword]; ok {
+			v.run.unsupportedKeyword(kPath+"/"+escapePointer(keyword), keyword)
+		}
+	}
+	// Only the schema being validated or the root document may set the base
+	// URI; an embedded $id would change how the references below it resolve.
+	if _, ok := s["$id"]; ok && kPath != "" && !isRootSchema(s, v.root) {
+		v.run.unsupportedKeyword(kPath+"/$id", "$id in a subschema")
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		key := ref + " " + iPath
+		target, err := resolvePointer(v.root, ref)
+		switch {
+		case err != nil:
+			v.fail(iPath, kPath+"/$ref", "%v", err)
+		case v.run.refs[key]:
+			v.fail(iPath, kPath+"/$ref", "circular $ref %s", ref)
+		default:
+			v.run.refs[key] = true
+			v.validate(target, instance, iPath, kPath+"/$ref")
+			delete(v.run.refs, key)
+		}
+	}
+
+	if instance == nil && s["nullable"] == true {
+		return
+	}
+
+	if t, ok := s["type"]; ok && !matchesType(t, instance) {
+		v.fail(iPath, kPath+"/type", "expected type %v, got %s", t, jsonType(instance))
+		return
+	}
+
+	if enum, ok := s["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, instance) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(iPath, kPath+"/enum", "value %s is not one of %s", jsonString(instance), jsonString(enum))
+		}
+	}
+
+	if c, ok := s["const"]; ok && !jsonEqual(c, instance) {
+		v.fail(iPath, kPath+"/const", "expected %s, got %s", jsonString(c), jsonString(instance))
+	}
+
+	switch value := instance.(type) {
+	case float64:
+		v.validateNumber(s, value, iPath, kPath)
+	case string:
+		v.validateString(s, value, iPath, kPath)
+	case []any:
+		v.validateArray(s, value, iPath, kPath)
+	case map[string]any:
+		v.validateObject(s, value, iPath, kPath)
+	}
+
+	v.validateApplicators(s, instance, iPath, kPath)
+}
+
+func (v *schemaValidator) validateNumber(s map[string]any, value float64, iPath, kPath string) {
+	if min, ok := s["minimum"].(float64); ok {
+		if s["exclusiveMinimum"] == true && value <= min {
+			v.fail(iPath, kPath+"/minimum", "must be > %v, got %v", min, value)
+		} else if value < min {
+			v.fail(iPath, kPath+"/minimum", "must be >= %v, got %v", min, value)
+		}
+	}
+	if max, ok := s["maximum"].(float64); ok {
+		if s["exclusiveMaximum"] == true && value >= max {
+			v.fail(iPath, kPath+"/maximum", "must be < %v, got %v", max, value)
+		} else if value > max {
+			v.fail(iPath, kPath+"/maximum", "must be <= %v, got %v", max, value)
+		}
+	}
+	if min, ok := s["exclusiveMinimum"].(float64); ok && value <= min {
+		v.fail(iPath, kPath+"/exclusiveMinimum", "must be > %v, got %v", min, value)
+	}
+	if max, ok := s["exclusiveMaximum"].(float64); ok && value >= max {
+		v.fail(iPath, kPath+"/exclusiveMaximum", "must be < %v, got %v", max, value)
+	}
+	if m, ok := s["multipleOf"].(float64); ok && m > 0 {
+		q := value / m
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(iPath, kPath+"/multipleOf", "must be a multiple of %v, got %v", m, value)
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(s map[string]any, value string, iPath, kPath string) {
+	length := utf8.RuneCountInString(value)
+	if min, ok := s["minLength"].(float64); ok && length < int(min) {
+		v.fail(iPath, kPath+"/minLength", "length must be >= %v, got %d", min, length)
+	}
+	if max, ok := s["maxLength"].(float64); ok && length > int(max) {
+		v.fail(iPath, kPath+"/maxLength", "length must be <= %v, got %d", max, length)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			v.fail(iPath, kPath+"/pattern", "invalid pattern %s: %v", pattern, err)
+		} else if !r.MatchString(value) {
+			v.fail(iPath, kPath+"/pattern", "%q does not match pattern %s", value, pattern)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(s map[string]any, value []any, iPath, kPath string) {
+	if min, ok := s["minItems"].(float64); ok && len(value) < int(min) {
+		v.fail(iPath, kPath+"/minItems", "must have at least %v items, got %d", min, len(value))
+	}
+	if max, ok := s["maxItems"].(float64); ok && len(value) > int(max) {
+		v.fail(iPath, kPath+"/maxItems", "must have at most %v items, got %d", max, len(value))
+	}
+	if s["uniqueItems"] == true {
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if jsonEqual(value[i], value[j]) {
+					v.fail(iPath, kPath+"/uniqueItems", "items %d and %d are equal", i, j)
+				}
+			}
+		}
+	}
+
+	prefix, _ := s["prefixItems"].([]any)
+	prefixPath := kPath + "/prefixItems"
+	// Older drafts and OpenAPI 3.0 write tuples as an items array.
+	if tuple, ok := s["items"].([]any); ok {
+		prefix = tuple
+		prefixPath = kPath + "/items"
+	}
+	for i, itemSchema := range prefix {
+		if i < len(value) {
+			v.validate(itemSchema, value[i], iPath+"/"+strconv.Itoa(i), prefixPath+"/"+strconv.Itoa(i))
+		}
+	}
+
+	if items, ok := s["items"]; ok {
+		if _, isTuple := items.([]any); !isTuple {
+			for i := len(prefix); i < len(value); i++ {
+				v.validate(items, value[i], iPath+"/"+strconv.Itoa(i), kPath+"/items")
+			}
+		}
+	}
+
+	if contains, ok := s["contains"]; ok {
+		matches := 0
+		for i, item := range value {
+			if v.isValid(contains, item, iPath+"/"+strconv.Itoa(i), kPath+"/contains") {
+				matches++
+			}
+		}
+
+		min := 1
+		if m, ok := s["minContains"].(float64); ok {
+			min = int(m)
+		}
+		if matches < min {
+			v.fail(iPath, kPath+"/contains", "must contain at least %d matching items, got %d", min, matches)
+		}
+		if max, ok := s["maxContains"].(float64); ok && matches > int(max) {
+			v.fail(iPath, kPath+"/maxContains", "must contain at most %v matching items, got %d", max, matches)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(s map[string]any, value map[string]any, iPath, kPath string) {
+	if min, ok := s["minProperties"].(float64); ok && len(value) < int(min) {
+		v.fail(iPath, kPath+"/minProperties", "must have at least %v properties, got %d", min, len(value))
+	}
+	if max, ok := s["maxProperties"].(float64); ok && len(value) > int(max) {
+		v.fail(iPath, kPath+"/maxProperties", "must have at most %v properties, got %d", max, len(value))
+	}
+
+	if required, ok := s["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := value[name]; !ok {
+				v.fail(iPath, kPath+"/required", "missing required property %q", name)
+			}
+		}
+	}
+
+	if dependent, ok := s["dependentRequired"].(map[string]any); ok {
+		for name, deps := range dependent {
+			if _, ok := value[name]; !ok {
+				continue
+			}
+			list, _ := deps.([]any)
+			for _, d := range list {
+				dep, _ := d.(string)
+				if _, ok := value[dep]; !ok {
+					v.fail(iPath, kPath+"/dependentRequired/"+escapePointer(name), "property %q requires %q", name, dep)
+				}
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]any)
+	patterns, _ := s["patternProperties"].(map[string]any)
+	additional, hasAdditional := s["additionalProperties"]
+	propertyNames, hasPropertyNames := s["propertyNames"]
+	dependentSchemas, _ := s["dependentSchemas"].(map[string]any)
+
+	for _, name := range sortedKeys(value) {
+		child := value[name]
+		childPath := iPath + "/" + escapePointer(name)
+		evaluated := false
+
+		if propSchema, ok := properties[name]; ok {
+			evaluated = true
+			v.validate(propSchema, child, childPath, kPath+"/properties/"+escapePointer(name))
+		}
+
+		for pattern, patternSchema := range patterns {
+			r, err := regexp.Compile(pattern)
+			if err != nil || !r.MatchString(name) {
+				continue
+			}
+			evaluated = true
+			v.validate(patternSchema, child, childPath, kPath+"/patternProperties/"+escapePointer(pattern))
+		}
+
+		if !evaluated && hasAdditional {
+			if additional == false {
+				v.fail(childPath, kPath+"/additionalProperties", "additional property %q is not allowed", name)
+			} else {
+				v.validate(additional, child, childPath, kPath+"/additionalProperties")
+			}
+		}
+
+		if hasPropertyNames {
+			v.validate(propertyNames, name, childPath, kPath+"/propertyNames")
+		}
+
+		if depSchema, ok := dependentSchemas[name]; ok {
+			v.validate(depSchema, value, iPath, kPath+"/dependentSchemas/"+escapePointer(name))
+		}
+	}
+}
+
+func (v *schemaValidator) validateApplicators(s map[string]any, instance any, iPath, kPath string) {
+	if allOf, ok := s["allOf"].([]any); ok {
+		for i, sub := range allOf {
+			v.validate(sub, instance, iPath, kPath+"/allOf/"+strconv.Itoa(i))
+		}
+	}
+
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		valid := false
+		for i, sub := range anyOf {
+			if v.isValid(sub, instance, iPath, kPath+"/anyOf/"+strconv.Itoa(i)) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			v.fail(iPath, kPath+"/anyOf", "must match at least one schema")
+		}
+	}
+
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		matches := 0
+		for i, sub := range oneOf {
+			if v.isValid(sub, instance, iPath, kPath+"/oneOf/"+strconv.Itoa(i)) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			v.fail(iPath, kPath+"/oneOf", "must match exactly one schema, matched %d", matches)
+		}
+	}
+
+	if not, ok := s["not"]; ok && v.isValid(not, instance, iPath, kPath+"/not") {
+		v.fail(iPath, kPath+"/not", "must not match schema")
+	}
+
+	if cond, ok := s["if"]; ok {
+		if v.isValid(cond, instance, iPath, kPath+"/if") {
+			if then, ok := s["then"]; ok {
+				v.validate(then, instance, iPath, kPath+"/then")
+			}
+		} else if otherwise, ok := s["else"]; ok {
+			v.validate(otherwise, instance, iPath, kPath+"/else")
+		}
+	}
+}
+
+// resolvePointer resolves a local reference such as #/$defs/user, or an
+// anchor such as #user, against the root document.
+func resolvePointer(root any, ref string) (any, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %s: only local references are supported", ref)
+	}
+
+	current := root
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer == "" {
+		return current, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		if schema := findAnchor(root, pointer); schema != nil {
+			return schema, nil
+		}
+		return nil, fmt.Errorf("unresolvable $ref %s", ref)
+	}
+
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]any:
+			next, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("unresolvable $ref %s", ref)
+			}
+			current = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("unresolvable $ref %s", ref)
+			}
+			current = node[i]
+		default:
+			return nil, fmt.Errorf("unresolvable $ref %s", ref)
+		}
+	}
+	return current, nil
+}
+
+func isRootSchema(s map[string]any, root any) bool {
+	r, ok := root.(map[string]any)
+	return ok && reflect.ValueOf(s).Pointer() == reflect.ValueOf(r).Pointer()
+}
+
+// findAnchor returns the subschema declaring the $anchor or $dynamicAnchor name.
+func findAnchor(node any, name string) map[string]any {
+	switch n := node.(type) {
+	case map[string]any:
+		if n["$anchor"] == name || n["$dynamicAnchor"] == name {
+			return n
+		}
+		for _, key := range sortedKeys(n) {
+			if key == "enum" || key == "const" || key == "default" || key == "examples" {
+				continue
+			}
+			if found := findAnchor(n[key], name); found != nil {
+				return found
+			}
+		}
+	case []any:
+		for _, item := range n {
+			if found := findAnchor(item, name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func matchesType(t any, instance any) bool {
+	switch types := t.(type) {
+	case string:
+		return isType(types, instance)
+	case []any:
+		for _, name := range types {
+			if s, ok := name.(string); ok && isType(s, instance) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isType(name string, instance any) bool {
+	actual := jsonType(instance)
+	if name == "number" && actual == "integer" {
+		return true
+	}
+	return name == actual
+}
+
+func jsonType(instance any) string {
+	switch value := instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", instance)
+}
+
+// jsonEqual compares two encoding/json decoded values structurally.
+func jsonEqual(a, b any) bool {
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !jsonEqual(xv, yv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func jsonString(value any) string {
+	b, err := marshalJSON(value, "")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(b)
+}
+
+// marshalJSON marshals without escaping HTML characters so values print as written.
+func marshalJSON(value any, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
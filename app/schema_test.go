@@ -0,0 +1,233 @@
+package app_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeJSON(t *testing.T, s string) any {
+	var v any
+	require.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+func TestValidateSchema(t *testing.T) {
+	testCases := []struct {
+		name       string
+		schema     string
+		instance   string
+		wantErrors []string
+	}{
+		{
+			name:     "Valid object",
+			schema:   `{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}}`,
+			instance: `{"id": 1, "name": "John"}`,
+		},
+		{
+			name:       "Wrong type",
+			schema:     `{"type": "object", "properties": {"id": {"type": "integer"}}}`,
+			instance:   `{"id": "1"}`,
+			wantErrors: []string{"/id: expected type integer, got string (/properties/id/type)"},
+		},
+		{
+			name:       "Missing required property",
+			schema:     `{"type": "object", "required": ["id", "name"]}`,
+			instance:   `{"id": 1}`,
+			wantErrors: []string{`/: missing required property "name" (/required)`},
+		},
+		{
+			name:       "Additional properties",
+			schema:     `{"properties": {"id": true}, "additionalProperties": false}`,
+			instance:   `{"id": 1, "extra": 2}`,
+			wantErrors: []string{`/extra: additional property "extra" is not allowed (/additionalProperties)`},
+		},
+		{
+			name:       "Array items and bounds",
+			schema:     `{"type": "array", "minItems": 1, "items": {"type": "number", "minimum": 0}}`,
+			instance:   `[1, -2.5]`,
+			wantErrors: []string{"/1: must be >= 0, got -2.5 (/items/minimum)"},
+		},
+		{
+			name:       "Prefix items",
+			schema:     `{"prefixItems": [{"type": "string"}], "items": {"type": "integer"}}`,
+			instance:   `["a", 1, "b"]`,
+			wantErrors: []string{"/2: expected type integer, got string (/items/type)"},
+		},
+		{
+			name:     "References to $defs",
+			schema:   `{"$defs": {"user": {"type": "object", "required": ["id"]}}, "type": "array", "items": {"$ref": "#/$defs/user"}}`,
+			instance: `[{"id": 1}, {"id": 2}]`,
+		},
+		{
+			name:       "Failing reference",
+			schema:     `{"$defs": {"user": {"type": "object", "required": ["id"]}}, "items": {"$ref": "#/$defs/user"}}`,
+			instance:   `[{}]`,
+			wantErrors: []string{`/0: missing required property "id" (/items/$ref/required)`},
+		},
+		{
+			name:       "Enum and const",
+			schema:     `{"properties": {"status": {"enum": ["active", "inactive"]}, "version": {"const": 2}}}`,
+			instance:   `{"status": "deleted", "version": 2.0}`,
+			wantErrors: []string{`/status: value "deleted" is not one of ["active","inactive"] (/properties/status/enum)`},
+		},
+		{
+			name:       "String constraints",
+			schema:     `{"type": "string", "minLength": 2, "pattern": "^[a-z]+$"}`,
+			instance:   `"A"`,
+			wantErrors: []string{"/: length must be >= 2, got 1 (/minLength)", `/: "A" does not match pattern ^[a-z]+$ (/pattern)`},
+		},
+		{
+			name:       "One of",
+			schema:     `{"oneOf": [{"type": "integer"}, {"type": "number"}]}`,
+			instance:   `1`,
+			wantErrors: []string{"/: must match exactly one schema, matched 2 (/oneOf)"},
+		},
+		{
+			name:     "Any of and not",
+			schema:   `{"anyOf": [{"type": "string"}, {"type": "null"}], "not": {"const": ""}}`,
+			instance: `null`,
+		},
+		{
+			name:       "If then else",
+			schema:     `{"if": {"properties": {"type": {"const": "company"}}}, "then": {"required": ["abn"]}, "else": {"required": ["dob"]}}`,
+			instance:   `{"type": "company"}`,
+			wantErrors: []string{`/: missing required property "abn" (/then/required)`},
+		},
+		{
+			name:       "Contains",
+			schema:     `{"contains": {"const": "admin"}, "uniqueItems": true}`,
+			instance:   `["user", "user"]`,
+			wantErrors: []string{"/: items 0 and 1 are equal (/uniqueItems)", "/: must contain at least 1 matching items, got 0 (/contains)"},
+		},
+		{
+			name:     "Nullable",
+			schema:   `{"type": "string", "nullable": true}`,
+			instance: `null`,
+		},
+		{
+			name:       "Unsupported keyword",
+			schema:     `{"allOf": [{"properties": {"id": {"type": "integer"}}}], "unevaluatedProperties": false}`,
+			instance:   `{"id": 1, "extra": true}`,
+			wantErrors: []string{"/: unsupported keyword unevaluatedProperties (/unevaluatedProperties)"},
+		},
+		{
+			name:       "Unsupported keyword in applicator",
+			schema:     `{"anyOf": [{"type": "array", "unevaluatedItems": false}, {"type": "string"}]}`,
+			instance:   `[1]`,
+			wantErrors: []string{"/: unsupported keyword unevaluatedItems (/anyOf/0/unevaluatedItems)"},
+		},
+		{
+			name:       "Embedded id",
+			schema:     `{"$id": "https://example.com/user", "properties": {"address": {"$id": "address", "type": "object"}}}`,
+			instance:   `{"address": {}}`,
+			wantErrors: []string{"/: unsupported keyword $id in a subschema (/properties/address/$id)"},
+		},
+		{
+			name:     "Recursive root with id",
+			schema:   `{"$id": "https://example.com/node", "type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#"}}}}`,
+			instance: `{"children": [{"children": []}]}`,
+		},
+		{
+			name:       "Anchor",
+			schema:     `{"$defs": {"id": {"$anchor": "id", "type": "integer"}}, "properties": {"id": {"$ref": "#id"}}}`,
+			instance:   `{"id": "1"}`,
+			wantErrors: []string{"/id: expected type integer, got string (/properties/id/$ref/type)"},
+		},
+		{
+			name:       "Circular reference",
+			schema:     `{"$ref": "#"}`,
+			instance:   `{}`,
+			wantErrors: []string{"/: circular $ref # (/$ref/$ref)"},
+		},
+		{
+			name:       "Circular reference through applicator",
+			schema:     `{"$defs": {"a": {"anyOf": [{"$ref": "#/$defs/b"}]}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`,
+			instance:   `1`,
+			wantErrors: []string{"/: must match at least one schema (/$ref/anyOf)"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schema := decodeJSON(t, tc.schema)
+			errs := app.ValidateSchema(schema, schema, decodeJSON(t, tc.instance))
+
+			var messages []string
+			for _, e := range errs {
+				messages = append(messages, e.Error())
+			}
+			assert.Equal(t, tc.wantErrors, messages)
+		})
+	}
+}
+
+func TestValidateResponseSchema(t *testing.T) {
+	tempDir := t.TempDir()
+	schemaFile := filepath.Join(tempDir, "schemas", "user.yaml")
+	require.NoError(t, os.Mkdir(filepath.Dir(schemaFile), 0o755))
+	require.NoError(t, os.WriteFile(schemaFile, []byte("type: object\nrequired: [id]\n"), 0o644))
+
+	testCases := []struct {
+		name    string
+		test    app.Test
+		body    string
+		wantErr string
+	}{
+		{
+			name: "Inline schema",
+			test: app.Test{Expect: app.TestExpect{Schema: map[string]any{
+				"type":     "object",
+				"required": []any{"id"},
+			}}},
+			body: `{"id": 1}`,
+		},
+		{
+			name: "Inline schema mismatch",
+			test: app.Test{Expect: app.TestExpect{Schema: map[string]any{
+				"properties": map[string]any{"id": map[string]any{"type": "string"}},
+			}}},
+			body:    `{"id": 1}`,
+			wantErr: "response does not match schema: /id: expected type string, got integer (/properties/id/type)",
+		},
+		{
+			name: "Schema file relative to test file",
+			test: app.Test{
+				File:   filepath.Join(tempDir, "users.yaml"),
+				Expect: app.TestExpect{Schema: "schemas/user.yaml"},
+			},
+			body:    `{"name": "John"}`,
+			wantErr: `response does not match schema: /: missing required property "id" (/required)`,
+		},
+		{
+			name:    "Missing schema file",
+			test:    app.Test{Expect: app.TestExpect{Schema: filepath.Join(tempDir, "missing.json")}},
+			body:    `{}`,
+			wantErr: "failed to load schema",
+		},
+		{
+			name:    "Body is not json",
+			test:    app.Test{Expect: app.TestExpect{Schema: map[string]any{}}},
+			body:    `OK`,
+			wantErr: "response does not match schema: response body is not valid json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app.Abdd{LastResponse: &app.LastResponse{Body: toPointer(tc.body)}}
+
+			err := a.ValidateResponse(&tc.test)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultReadyTimeout  = 30 * time.Second
+	defaultReadyInterval = 100 * time.Millisecond
+	serviceStopGrace     = 5 * time.Second
+	serviceLogLimit      = 1 << 20
+	serviceLogTailLines  = 50
+)
+
+// ServiceConfig describes a long-running process started before the suite.
+type ServiceConfig struct {
+	Name      string            `yaml:"name"`
+	Command   string            `yaml:"command"`
+	Directory string            `yaml:"directory,omitempty"`
+	Env       map[string]string `yaml:"env,omitempty"`
+	Shell     string            `yaml:"shell,omitempty"`
+	Ready     *ReadyCheck       `yaml:"ready,omitempty"`
+}
+
+// ReadyCheck waits for a service to accept TCP connections, answer an HTTP
+// health URL with a non-error status, or print a log line matching a regex.
+type ReadyCheck struct {
+	TCP      string        `yaml:"tcp,omitempty"`
+	HTTP     string        `yaml:"http,omitempty"`
+	Log      string        `yaml:"log,omitempty"`
+	Timeout  time.Duration `yaml:"timeout,omitempty"`
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+// service is a running ServiceConfig.
+type service struct {
+	config ServiceConfig
+	cmd    *exec.Cmd
+	logs   *serviceLog
+	done   chan struct{}
+	err    error
+	stop   sync.Once
+}
+
+// serviceGroup is the set of running services. It is shared with the signal
+// handler, which may stop them while Run is still starting them.
+type serviceGroup struct {
+	mu       sync.Mutex
+	services []*service
+}
+
+func (g *serviceGroup) add(s *service) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.services = append(g.services, s)
+}
+
+// stop terminates the services in reverse start order.
+func (g *serviceGroup) stop() {
+	if g == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i := len(g.services) - 1; i >= 0; i-- {
+		g.services[i].terminate()
+	}
+	g.services = nil
+}
+
+func (g *serviceGroup) each(fn func(*service)) {
+	if g == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, s := range g.services {
+		fn(s)
+	}
+}
+
+// serviceLog collects the combined output of a service.
+type serviceLog struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	mark int
+}
+
+func (l *serviceLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Keep the most recent output once the limit is reached.
+	if over := l.buf.Len() + len(p) - serviceLogLimit; over > 0 {
+		l.buf.Next(min(over, l.buf.Len()))
+		l.mark = max(l.mark-over, 0)
+	}
+	return l.buf.Write(p)
+}
+
+func (l *serviceLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
+
+// Mark remembers the current end of the log so Since returns only newer output.
+func (l *serviceLog) Mark() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.mark = l.buf.Len()
+}
+
+// Since returns the output written after the last Mark.
+func (l *serviceLog) Since() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return string(l.buf.Bytes()[l.mark:])
+}
+
+// InterruptedError is returned by Run when the process received a signal.
+// Run stops after the current test, runs the after hooks and stops the
+// services before returning it.
+type InterruptedError struct {
+	Signal os.Signal
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("interrupted by %s", e.Signal)
+}
+
+// StartServices launches every configured service and waits until each is ready.
+// Services already started are stopped again when one fails to start.
+func (a *Abdd) StartServices() error {
+	if a.services == nil {
+		a.services = &serviceGroup{}
+	}
+
+	for _, config := range a.Global.Config.Services {
+		if err := a.interrupted(); err != nil {
+			a.StopServices()
+			return err
+		}
+
+		s, err := startService(config)
+		if err != nil {
+			a.StopServices()
+			return err
+		}
+		a.services.add(s)
+
+		if err := s.waitReady(a.interrupt); err != nil {
+			a.StopServices()
+			if err := a.interrupted(); err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %s: %v\n%s", ErrServiceNotReady, config.Name, err, tail(s.logs.String(), serviceLogTailLines))
+		}
+
+		if a.Global.Config.Verbose {
+			fmt.Printf("%s Started service %s\n", infoText("•"), config.Name)
+		}
+	}
+	return nil
+}
+
+// StopServices terminates the process group of every running service.
+func (a *Abdd) StopServices() {
+	a.services.stop()
+}
+
+// handleSignals turns the first interrupt into a cancelled run, so Run can
+// stop after the current test and clean up. A second interrupt stops the
+// services at once and lets the signal end the process. The returned
+// function removes the handler.
+func (a *Abdd) handleSignals() func() {
+	if a.services == nil {
+		a.services = &serviceGroup{}
+	}
+	services := a.services
+	a.interrupt = make(chan struct{})
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			a.signal = sig
+			close(a.interrupt)
+			fmt.Fprintf(os.Stderr, "\n%s, stopping after the current test (repeat to stop now)\n", sig)
+		case <-done:
+			return
+		}
+
+		select {
+		case sig := <-signals:
+			services.stop()
+			signal.Stop(signals)
+			if p, err := os.FindProcess(os.Getpid()); err == nil {
+				_ = p.Signal(sig)
+			}
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// interrupted returns an InterruptedError once a signal has cancelled the run.
+func (a *Abdd) interrupted() error {
+	select {
+	case <-a.interrupt:
+		return &InterruptedError{Signal: a.signal}
+	default:
+		return nil
+	}
+}
+
+// markServiceLogs is called before each test so failures show only new output.
+func (a *Abdd) markServiceLogs() {
+	a.services.each(func(s *service) {
+		s.logs.Mark()
+	})
+}
+
+func startService(config ServiceConfig) (*service, error) {
+	if config.Command == "" {
+		return nil, fmt.Errorf("service %s has no command", config.Name)
+	}
+
+	name, args := shellCommand(config.Shell, config.Command)
+	cmd := exec.Command(name, args...)
+	cmd.Dir = config.Directory
+	cmd.WaitDelay = time.Second
+	setProcessGroup(cmd)
+
+	if len(config.Env) > 0 {
+		cmd.Env = os.Environ()
+		for _, key := range sortedStringKeys(config.Env) {
+			cmd.Env = append(cmd.Env, key+"="+config.Env[key])
+		}
+	}
+
+	s := &service{config: config, cmd: cmd, logs: &serviceLog{}, done: make(chan struct{})}
+	cmd.Stdout = s.logs
+	cmd.Stderr = s.logs
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start service %s: %w", config.Name, err)
+	}
+
+	go func() {
+		s.err = cmd.Wait()
+		close(s.done)
+	}()
+
+	return s, nil
+}
+
+func (s *service) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitReady polls the ready check until it passes, times out, or interrupt is closed.
+func (s *service) waitReady(interrupt <-chan struct{}) error {
+	check := s.config.Ready
+	if check == nil {
+		return nil
+	}
+
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = defaultReadyTimeout
+	}
+	interval := check.Interval
+	if interval <= 0 {
+		interval = defaultReadyInterval
+	}
+
+	var pattern *regexp.Regexp
+	if check.Log != "" {
+		var err error
+		if pattern, err = regexp.Compile(check.Log); err != nil {
+			return fmt.Errorf("invalid log pattern %s: %w", check.Log, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client := &http.Client{Timeout: interval * 10}
+	for {
+		if s.exited() {
+			return fmt.Errorf("exited before it was ready: %v", s.err)
+		}
+
+		if s.ready(ctx, client, pattern) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not ready after %s", timeout)
+		case <-interrupt:
+			return fmt.Errorf("interrupted before it was ready")
+		case <-s.done:
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (s *service) ready(ctx context.Context, client *http.Client, pattern *regexp.Regexp) bool {
+	check := s.config.Ready
+
+	if check.TCP != "" {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", check.TCP)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+	}
+
+	if check.HTTP != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.HTTP, nil)
+		if err != nil {
+			return false
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return false
+		}
+	}
+
+	if pattern != nil && !pattern.MatchString(s.logs.String()) {
+		return false
+	}
+
+	return true
+}
+
+// terminate asks the process group to stop and kills it after a grace period.
+func (s *service) terminate() {
+	s.stop.Do(func() {
+		if s.exited() {
+			// The leader is gone but its children may still be running.
+			_ = killProcessGroup(s.cmd)
+			return
+		}
+
+		_ = terminateProcessGroup(s.cmd)
+		select {
+		case <-s.done:
+		case <-time.After(serviceStopGrace):
+			_ = killProcessGroup(s.cmd)
+			<-s.done
+		}
+	})
+}
+
+// tail returns the last n lines of text.
+func tail(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
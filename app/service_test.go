@@ -0,0 +1,216 @@
+//go:build !windows
+
+package app_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// processRunning reports whether pid is alive and not a zombie waiting to be reaped.
+func processRunning(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat))
+	return len(fields) < 3 || fields[2] != "Z"
+}
+
+func TestServices(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	health := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer health.Close()
+
+	testCases := []struct {
+		name    string
+		service app.ServiceConfig
+		wantErr string
+	}{
+		{
+			name: "Log line readiness",
+			service: app.ServiceConfig{
+				Name:    "api",
+				Command: "echo booting; sleep 0.2; echo listening on $PORT; sleep 30",
+				Env:     map[string]string{"PORT": "8080"},
+				Ready:   &app.ReadyCheck{Log: `listening on \d+`, Timeout: 5 * time.Second},
+			},
+		},
+		{
+			name: "TCP and HTTP readiness",
+			service: app.ServiceConfig{
+				Name:    "api",
+				Command: "sleep 30",
+				Ready:   &app.ReadyCheck{TCP: listener.Addr().String(), HTTP: health.URL, Timeout: 5 * time.Second},
+			},
+		},
+		{
+			name: "Exits before ready",
+			service: app.ServiceConfig{
+				Name:    "api",
+				Command: "echo missing config; exit 1",
+				Ready:   &app.ReadyCheck{Log: "ready", Timeout: 5 * time.Second},
+			},
+			wantErr: "service not ready: api: exited before it was ready: exit status 1\nmissing config",
+		},
+		{
+			name: "Readiness timeout",
+			service: app.ServiceConfig{
+				Name:    "api",
+				Command: "echo still starting; sleep 30",
+				Ready:   &app.ReadyCheck{Log: "ready", Timeout: 300 * time.Millisecond, Interval: 50 * time.Millisecond},
+			},
+			wantErr: "service not ready: api: not ready after 300ms\nstill starting",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app.Abdd{Global: app.Global{Config: app.Config{Services: []app.ServiceConfig{tc.service}}}}
+			err := a.StartServices()
+			defer a.StopServices()
+
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, app.ErrServiceNotReady)
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestStopServicesKillsProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	a := &app.Abdd{Global: app.Global{Config: app.Config{Services: []app.ServiceConfig{{
+		Name:    "worker",
+		Command: "sleep 30 & echo $! > " + pidFile + "; echo started; wait",
+		Ready:   &app.ReadyCheck{Log: "started", Timeout: 5 * time.Second},
+	}}}}}
+	require.NoError(t, a.StartServices())
+
+	b, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	require.NoError(t, err)
+	require.True(t, processRunning(pid))
+
+	a.StopServices()
+	assert.Eventually(t, func() bool { return !processRunning(pid) }, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestRunInterruptedDuringStartup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	a := &app.Abdd{Global: app.Global{Config: app.Config{Services: []app.ServiceConfig{{
+		Name:    "api",
+		Command: "sleep 30 & echo $! > " + pidFile + "; wait",
+		Ready:   &app.ReadyCheck{Log: "never", Timeout: 30 * time.Second},
+	}}}}}
+
+	go func() {
+		assert.Eventually(t, func() bool { _, err := os.Stat(pidFile); return err == nil }, 5*time.Second, 20*time.Millisecond)
+		_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+	}()
+
+	start := time.Now()
+	err := a.Run()
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	var interrupted *app.InterruptedError
+	require.True(t, errors.As(err, &interrupted), "got %v", err)
+	assert.Equal(t, os.Interrupt, interrupted.Signal)
+
+	b, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return !processRunning(pid) }, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestRunInterruptedRunsAfterHooks(t *testing.T) {
+	dir := t.TempDir()
+	testFolder := filepath.Join(dir, "tests")
+	require.NoError(t, os.Mkdir(testFolder, 0o755))
+	marker := filepath.Join(dir, "after_all")
+
+	configFile := filepath.Join(dir, "abdd.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+global:
+  config:
+    services:
+      - name: worker
+        command: sleep 30
+  hooks:
+    after_all:
+      - command: {command: "touch `+marker+`"}
+`), 0o644))
+
+	// The first test interrupts the runner, so the second never runs.
+	require.NoError(t, os.WriteFile(filepath.Join(testFolder, "tests.yaml"), []byte(`
+tests:
+  - name: interrupt
+    command: {command: "kill -INT $PPID; sleep 0.2"}
+  - name: skipped
+    command: {command: "touch `+filepath.Join(dir, "skipped")+`"}
+`), 0o644))
+
+	a, err := app.New(app.AbddArgs{ConfigFile: configFile, Folders: []string{testFolder}})
+	require.NoError(t, err)
+
+	err = a.Run()
+	var interrupted *app.InterruptedError
+	require.True(t, errors.As(err, &interrupted), "got %v", err)
+
+	assert.FileExists(t, marker)
+	assert.NoFileExists(t, filepath.Join(dir, "skipped"))
+}
+
+func TestNewResolvesServiceDirectoryFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, "config")
+	workDir := filepath.Join(dir, "server")
+	testFolder := filepath.Join(dir, "tests")
+	for _, d := range []string{configDir, workDir, testFolder} {
+		require.NoError(t, os.Mkdir(d, 0o755))
+	}
+
+	configFile := filepath.Join(configDir, "abdd.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+global:
+  config:
+    services:
+      - name: api
+        command: pwd; sleep 30
+        directory: ../server
+        ready:
+          log: (?m)/server$
+          timeout: 5s
+`), 0o644))
+
+	// The directory is relative to the config file, not the working directory.
+	a, err := app.New(app.AbddArgs{ConfigFile: configFile, Folders: []string{testFolder}})
+	require.NoError(t, err)
+	assert.Equal(t, workDir, a.Global.Config.Services[0].Directory)
+
+	require.NoError(t, a.StartServices())
+	a.StopServices()
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+)
+
+// defaultSession is the name of the session used by requests that do not
+// name one when cookies are enabled globally.
+const defaultSession = ""
+
+// sessionJar returns the cookie jar for the named session, creating it on first use.
+// Requests without a session name only get a jar when cookies are enabled in the config.
+func (a *Abdd) sessionJar(name string) (http.CookieJar, error) {
+	if name == defaultSession && !a.Global.Config.Cookies {
+		return nil, nil
+	}
+
+	if a.Sessions == nil {
+		a.Sessions = make(map[string]http.CookieJar)
+	}
+
+	if jar, ok := a.Sessions[name]; ok {
+		return jar, nil
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+	a.Sessions[name] = jar
+
+	return jar, nil
+}
+
+// ClearSession discards all cookies stored for the named session.
+func (a *Abdd) ClearSession(name string) {
+	delete(a.Sessions, name)
+}
@@ -0,0 +1,101 @@
+package app_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSessionServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "user", Value: r.URL.Query().Get("as"), Path: "/"})
+		case "/me":
+			c, err := r.Cookie("user")
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(c.Value))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSessions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cookies  bool
+		requests []app.TestRequest
+		expects  func(*app.Abdd)
+	}{
+		{
+			name: "Cookies disabled by default",
+			requests: []app.TestRequest{
+				{Method: "GET", URL: "/login?as=admin"},
+				{Method: "GET", URL: "/me"},
+			},
+			expects: func(a *app.Abdd) {
+				assert.Equal(t, http.StatusUnauthorized, *a.LastResponse.Code)
+			},
+		},
+		{
+			name:    "Global cookie jar",
+			cookies: true,
+			requests: []app.TestRequest{
+				{Method: "GET", URL: "/login?as=admin"},
+				{Method: "GET", URL: "/me"},
+			},
+			expects: func(a *app.Abdd) {
+				assert.Equal(t, http.StatusOK, *a.LastResponse.Code)
+				assert.Equal(t, "admin", *a.LastResponse.Body)
+			},
+		},
+		{
+			name: "Named sessions are isolated",
+			requests: []app.TestRequest{
+				{Method: "GET", URL: "/login?as=admin", Session: "admin"},
+				{Method: "GET", URL: "/login?as=user", Session: "user"},
+				{Method: "GET", URL: "/me", Session: "admin"},
+			},
+			expects: func(a *app.Abdd) {
+				assert.Equal(t, "admin", *a.LastResponse.Body)
+				assert.Len(t, a.Sessions, 2)
+			},
+		},
+		{
+			name: "Clearing a session drops its cookies",
+			requests: []app.TestRequest{
+				{Method: "GET", URL: "/login?as=admin", Session: "admin"},
+				{Method: "GET", URL: "/me", Session: "admin", ClearSession: true},
+			},
+			expects: func(a *app.Abdd) {
+				assert.Equal(t, http.StatusUnauthorized, *a.LastResponse.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newSessionServer(t)
+			a := &app.Abdd{
+				Global: app.Global{Config: app.Config{BaseURL: server.URL, Cookies: tc.cookies}},
+				Store:  map[string]any{},
+				Client: server.Client(),
+			}
+
+			for _, r := range tc.requests {
+				err := a.MakeRequest(&app.Test{Request: &r})
+				require.NoError(t, err)
+			}
+
+			tc.expects(a)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const snapshotDir = "__snapshots__"
+
+// SnapshotExpect enables snapshot testing of the response body. In YAML it is
+// written as true or as an object listing the paths to ignore.
+type SnapshotExpect struct {
+	Enabled bool     `yaml:"-"`
+	Ignore  []string `yaml:"ignore,omitempty"`
+}
+
+func (s *SnapshotExpect) UnmarshalYAML(unmarshal func(any) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		*s = SnapshotExpect{Enabled: enabled}
+		return nil
+	}
+
+	var options struct {
+		Ignore []string `yaml:"ignore"`
+	}
+	if err := unmarshal(&options); err != nil {
+		return fmt.Errorf("snapshot must be a boolean or an object: %w", err)
+	}
+	*s = SnapshotExpect{Enabled: true, Ignore: options.Ignore}
+	return nil
+}
+
+// SnapshotPath returns where the snapshot of the test is stored: a
+// __snapshots__ directory next to the test file. Steps are stored in a
+// directory named after the test they belong to.
+func SnapshotPath(t *Test) string {
+	dir, stem := ".", "tests"
+	if t.File != "" {
+		dir = filepath.Dir(t.File)
+		stem = strings.TrimSuffix(filepath.Base(t.File), filepath.Ext(t.File))
+	}
+
+	names := []string{slug(t.Name) + ".json"}
+	for p := t.Parent; p != nil; p = p.Parent {
+		names = append([]string{slug(p.Name)}, names...)
+	}
+	return filepath.Join(append([]string{dir, snapshotDir, stem}, names...)...)
+}
+
+// checkSnapshotName rejects tests whose name, or the name of a test they are
+// a step of, has no letters or digits to name the snapshot after.
+func checkSnapshotName(t *Test) error {
+	for test := t; test != nil; test = test.Parent {
+		if slug(test.Name) == "" {
+			return fmt.Errorf("cannot name snapshot after test %q: the name needs letters or digits", test.Name)
+		}
+	}
+	return nil
+}
+
+func slug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// normalizeSnapshot decodes the body and masks the ignored paths. Bodies that
+// are not JSON are snapshotted as a string.
+func normalizeSnapshot(body string, ignore []string) any {
+	var doc any
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		return body
+	}
+	return maskPaths(doc, ignore)
+}
+
+func (a *Abdd) validateSnapshot(t *Test) error {
+	if err := checkSnapshotName(t); err != nil {
+		return err
+	}
+
+	body := ""
+	if a.LastResponse.Body != nil {
+		body = *a.LastResponse.Body
+	}
+	actual := normalizeSnapshot(body, t.Expect.Snapshot.Ignore)
+	path := SnapshotPath(t)
+
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) || a.Global.Config.UpdateSnapshots {
+		return writeSnapshot(path, actual)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var expected any
+	if err := json.Unmarshal(existing, &expected); err != nil {
+		return fmt.Errorf("failed to unmarshal snapshot %s: %w", path, err)
+	}
+
+	diffs := DiffJSON(expected, actual, DiffOptions{})
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(diffs))
+	for i, d := range diffs {
+		messages[i] = d.String()
+	}
+	return fmt.Errorf("%w: %s: %s", ErrSnapshotMismatch, path, strings.Join(messages, "; "))
+}
+
+func writeSnapshot(path string, value any) error {
+	b, err := marshalJSON(value, "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package app_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotExpectUnmarshal(t *testing.T) {
+	var expect app.TestExpect
+	require.NoError(t, yaml.Unmarshal([]byte("snapshot: true"), &expect))
+	assert.Equal(t, &app.SnapshotExpect{Enabled: true}, expect.Snapshot)
+
+	expect = app.TestExpect{}
+	require.NoError(t, yaml.Unmarshal([]byte("snapshot:\n  ignore: [id, items.*.created_at]"), &expect))
+	assert.Equal(t, &app.SnapshotExpect{Enabled: true, Ignore: []string{"id", "items.*.created_at"}}, expect.Snapshot)
+}
+
+func TestSnapshotPath(t *testing.T) {
+	test := &app.Test{Name: "Create new business!", File: filepath.Join("tests", "business.yaml")}
+	assert.Equal(t, filepath.Join("tests", "__snapshots__", "business", "create-new-business.json"), app.SnapshotPath(test))
+
+	step := &app.Test{Name: "step 1", File: test.File, Parent: test}
+	assert.Equal(t, filepath.Join("tests", "__snapshots__", "business", "create-new-business", "step-1.json"), app.SnapshotPath(step))
+}
+
+func TestValidateResponseSnapshotName(t *testing.T) {
+	a := &app.Abdd{LastResponse: &app.LastResponse{Body: toPointer(`{}`)}}
+	test := &app.Test{Name: "!!!", File: filepath.Join(t.TempDir(), "users.yaml"), Expect: app.TestExpect{Snapshot: &app.SnapshotExpect{Enabled: true}}}
+
+	err := a.ValidateResponse(test)
+	assert.EqualError(t, err, `cannot name snapshot after test "!!!": the name needs letters or digits`)
+	assert.NoDirExists(t, filepath.Join(filepath.Dir(test.File), "__snapshots__"))
+}
+
+func TestRunTestStepSnapshots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "users.yaml")
+	step := func(url string) app.Test {
+		return app.Test{
+			Request: &app.TestRequest{Method: http.MethodGet, URL: url},
+			Expect:  app.TestExpect{Snapshot: &app.SnapshotExpect{Enabled: true}},
+		}
+	}
+	first := app.Test{Name: "Create user", File: file, Steps: []app.Test{step("/users")}}
+	second := app.Test{Name: "Create admin", File: file, Steps: []app.Test{step("/admins")}}
+
+	for _, update := range []bool{true, false} {
+		a := &app.Abdd{
+			Global: app.Global{Config: app.Config{BaseURL: server.URL, UpdateSnapshots: update}},
+			Store:  map[string]any{},
+			Client: server.Client(),
+		}
+		for _, test := range []app.Test{first, second} {
+			_, err := a.RunTest(&test)
+			require.NoError(t, err, test.Name)
+		}
+	}
+
+	snapshot, err := os.ReadFile(filepath.Join(filepath.Dir(file), "__snapshots__", "users", "create-admin", "step-1.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"path": "/admins"}`, string(snapshot))
+}
+
+func TestValidateResponseSnapshot(t *testing.T) {
+	tempDir := t.TempDir()
+	test := app.Test{
+		Name: "Get user",
+		File: filepath.Join(tempDir, "users.yaml"),
+		Expect: app.TestExpect{Snapshot: &app.SnapshotExpect{
+			Enabled: true,
+			Ignore:  []string{"id", "roles.*.granted_at"},
+		}},
+	}
+
+	validate := func(body string, update bool) error {
+		a := &app.Abdd{
+			Global:       app.Global{Config: app.Config{UpdateSnapshots: update}},
+			LastResponse: &app.LastResponse{Body: toPointer(body)},
+		}
+		return a.ValidateResponse(&test)
+	}
+
+	// The first run records the snapshot.
+	err := validate(`{"id": 1, "name": "John", "roles": [{"name": "admin", "granted_at": "2025-01-01"}]}`, false)
+	require.NoError(t, err)
+
+	snapshot, err := os.ReadFile(app.SnapshotPath(&test))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": "<ignored>", "name": "John", "roles": [{"name": "admin", "granted_at": "<ignored>"}]}`, string(snapshot))
+
+	// Ignored paths may change between runs.
+	err = validate(`{"id": 2, "name": "John", "roles": [{"name": "admin", "granted_at": "2025-02-02"}]}`, false)
+	assert.NoError(t, err)
+
+	err = validate(`{"id": 3, "name": "Jane", "roles": [], "email": "jane@example.com"}`, false)
+	assert.ErrorIs(t, err, app.ErrSnapshotMismatch)
+	assert.ErrorContains(t, err, `name: expected "John", got "Jane"; roles.0: missing, expected {"granted_at":"<ignored>","name":"admin"}; email: unexpected "jane@example.com"`)
+
+	// Update mode rewrites the snapshot.
+	err = validate(`{"id": 3, "name": "Jane", "roles": []}`, true)
+	require.NoError(t, err)
+	err = validate(`{"id": 4, "name": "Jane", "roles": []}`, false)
+	assert.NoError(t, err)
+}
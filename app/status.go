@@ -0,0 +1,125 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusExpect is the set of accepted status codes. In YAML it is written as a
+// code (200), a class (2xx), a list of codes and classes ([200, 204]) or as an
+// exclusion ({not: 500}).
+type StatusExpect struct {
+	In  []string
+	Not []string
+}
+
+// NewStatusExpect accepts exactly the given status codes.
+func NewStatusExpect(codes ...int) *StatusExpect {
+	s := &StatusExpect{}
+	for _, code := range codes {
+		s.In = append(s.In, strconv.Itoa(code))
+	}
+	return s
+}
+
+func (s *StatusExpect) UnmarshalYAML(unmarshal func(any) error) error {
+	var value any
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+
+	if m, ok := value.(map[string]any); ok {
+		not, ok := m["not"]
+		if !ok || len(m) != 1 {
+			return fmt.Errorf("status object only supports not")
+		}
+
+		patterns, err := statusPatterns(not)
+		if err != nil {
+			return err
+		}
+		*s = StatusExpect{Not: patterns}
+		return nil
+	}
+
+	patterns, err := statusPatterns(value)
+	if err != nil {
+		return err
+	}
+	*s = StatusExpect{In: patterns}
+	return nil
+}
+
+func statusPatterns(value any) ([]string, error) {
+	list, ok := value.([]any)
+	if !ok {
+		list = []any{value}
+	}
+
+	patterns := make([]string, 0, len(list))
+	for _, v := range list {
+		pattern := strings.ToLower(fmt.Sprintf("%v", v))
+		if !isStatusPattern(pattern) {
+			return nil, fmt.Errorf("invalid status %v: expected a code such as 200 or a class such as 2xx", v)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+func isStatusPattern(pattern string) bool {
+	if len(pattern) != 3 || pattern[0] < '1' || pattern[0] > '5' {
+		return false
+	}
+	if pattern[1:] == "xx" {
+		return true
+	}
+	_, err := strconv.Atoi(pattern)
+	return err == nil
+}
+
+func matchesStatus(pattern string, code int) bool {
+	if strings.HasSuffix(pattern, "xx") {
+		return strconv.Itoa(code)[:1] == pattern[:1]
+	}
+	return pattern == strconv.Itoa(code)
+}
+
+// Matches reports whether the status code is accepted.
+func (s *StatusExpect) Matches(code int) bool {
+	for _, pattern := range s.Not {
+		if matchesStatus(pattern, code) {
+			return false
+		}
+	}
+	if len(s.In) == 0 {
+		return true
+	}
+	for _, pattern := range s.In {
+		if matchesStatus(pattern, code) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *StatusExpect) String() string {
+	var parts []string
+	switch len(s.In) {
+	case 0:
+	case 1:
+		parts = append(parts, s.In[0])
+	default:
+		parts = append(parts, "one of ["+strings.Join(s.In, ", ")+"]")
+	}
+
+	switch len(s.Not) {
+	case 0:
+	case 1:
+		parts = append(parts, "not "+s.Not[0])
+	default:
+		parts = append(parts, "none of ["+strings.Join(s.Not, ", ")+"]")
+	}
+	return strings.Join(parts, " and ")
+}
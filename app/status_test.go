@@ -0,0 +1,55 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusExpect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		yaml     string
+		code     int
+		wantErr  string
+		parseErr bool
+	}{
+		{name: "Exact code", yaml: "status: 200", code: 200},
+		{name: "Exact code mismatch", yaml: "status: 200", code: 201, wantErr: "unexpected status code: expected 200, got 201"},
+		{name: "Class", yaml: "status: 2xx", code: 204},
+		{name: "Upper case class", yaml: "status: 4XX", code: 404},
+		{name: "Class mismatch", yaml: "status: 2xx", code: 500, wantErr: "unexpected status code: expected 2xx, got 500"},
+		{name: "Set", yaml: "status: [200, 204]", code: 204},
+		{name: "Set mismatch", yaml: "status: [200, 204]", code: 202, wantErr: "unexpected status code: expected one of [200, 204], got 202"},
+		{name: "Mixed set", yaml: "status: [3xx, 200]", code: 302},
+		{name: "Not", yaml: "status: {not: 500}", code: 404},
+		{name: "Not mismatch", yaml: "status: {not: 5xx}", code: 503, wantErr: "unexpected status code: expected not 5xx, got 503"},
+		{name: "Not set mismatch", yaml: "status: {not: [500, 502]}", code: 502, wantErr: "unexpected status code: expected none of [500, 502], got 502"},
+		{name: "Invalid code", yaml: "status: 2000", parseErr: true},
+		{name: "Invalid class", yaml: "status: 9xx", parseErr: true},
+		{name: "Unknown operator", yaml: "status: {in: 200}", parseErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var expect app.TestExpect
+			err := yaml.Unmarshal([]byte(tc.yaml), &expect)
+			if tc.parseErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			a := &app.Abdd{LastResponse: &app.LastResponse{Code: toPointer(tc.code)}}
+			err = a.ValidateResponse(&app.Test{Expect: expect})
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
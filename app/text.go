@@ -0,0 +1,87 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TextExpect asserts on a plain-text value such as a non-JSON response body.
+type TextExpect struct {
+	Equals    *string `yaml:"equals,omitempty"`
+	Contains  string  `yaml:"contains,omitempty"`
+	Matches   string  `yaml:"matches,omitempty"`
+	LineCount *int    `yaml:"line_count,omitempty"`
+}
+
+// validate checks the value; name describes the value in error messages.
+func (e *TextExpect) validate(name, value string) error {
+	if e.Equals != nil && value != *e.Equals {
+		return fmt.Errorf("%w: expected %s to be %q, got %q", ErrTextNotEqual, name, *e.Equals, value)
+	}
+
+	if e.Contains != "" && !strings.Contains(value, e.Contains) {
+		return fmt.Errorf("%w: expected %s to contain %q, got %q", ErrTextNotEqual, name, e.Contains, value)
+	}
+
+	if e.Matches != "" {
+		matched, err := regexp.MatchString(e.Matches, value)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %s: %w", e.Matches, err)
+		}
+		if !matched {
+			return fmt.Errorf("%w: expected %s to match %s, got %q", ErrTextNotEqual, name, e.Matches, value)
+		}
+	}
+
+	if e.LineCount != nil {
+		if lines := countLines(value); lines != *e.LineCount {
+			return fmt.Errorf("%w: expected %s to have %d lines, got %d", ErrTextNotEqual, name, *e.LineCount, lines)
+		}
+	}
+	return nil
+}
+
+func (a *Abdd) replaceVariablesInTextExpect(e *TextExpect) *TextExpect {
+	replaced := *e
+	if e.Equals != nil {
+		equals := a.replaceVariablesInText(*e.Equals)
+		replaced.Equals = &equals
+	}
+	replaced.Contains = a.replaceVariablesInText(e.Contains)
+	replaced.Matches = a.replaceVariablesInText(e.Matches)
+	return &replaced
+}
+
+// countLines counts lines the way wc -l would if the last line were terminated.
+func countLines(text string) int {
+	if text == "" {
+		return 0
+	}
+	return len(strings.Split(strings.TrimSuffix(text, "\n"), "\n"))
+}
+
+func (a *Abdd) validateBodyBytes(t *Test) error {
+	body := ""
+	if a.LastResponse.Body != nil {
+		body = *a.LastResponse.Body
+	}
+
+	if t.Expect.BodySize != nil && len(body) != *t.Expect.BodySize {
+		return fmt.Errorf("%w: expected body size to be %d bytes, got %d", ErrTextNotEqual, *t.Expect.BodySize, len(body))
+	}
+
+	if t.Expect.BodySHA256 != "" {
+		sum := sha256.Sum256([]byte(body))
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, t.Expect.BodySHA256) {
+			return fmt.Errorf("%w: expected body sha256 to be %s, got %s", ErrTextNotEqual, t.Expect.BodySHA256, actual)
+		}
+	}
+
+	if t.Expect.Text != nil {
+		return t.Expect.Text.validate("body", body)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResponseText(t *testing.T) {
+	testCases := []struct {
+		name    string
+		expect  string
+		body    string
+		wantErr string
+	}{
+		{
+			name: "All operators match",
+			expect: `
+text:
+  equals: "OK\nready\n"
+  contains: ready
+  matches: ^OK
+  line_count: 2`,
+			body: "OK\nready\n",
+		},
+		{
+			name:   "Empty body equals empty string",
+			expect: `text: {equals: ""}`,
+			body:   "",
+		},
+		{
+			name:    "Not equal",
+			expect:  `text: {equals: OK}`,
+			body:    "FAIL",
+			wantErr: `text not equal: expected body to be "OK", got "FAIL"`,
+		},
+		{
+			name:    "Does not contain",
+			expect:  `text: {contains: pong}`,
+			body:    "ping",
+			wantErr: `text not equal: expected body to contain "pong", got "ping"`,
+		},
+		{
+			name:    "Does not match",
+			expect:  `text: {matches: '^\d+$'}`,
+			body:    "abc",
+			wantErr: `text not equal: expected body to match ^\d+$, got "abc"`,
+		},
+		{
+			name:    "Invalid pattern",
+			expect:  `text: {matches: '('}`,
+			body:    "abc",
+			wantErr: "invalid pattern (",
+		},
+		{
+			name:    "Line count mismatch",
+			expect:  `text: {line_count: 1}`,
+			body:    "a\nb",
+			wantErr: "text not equal: expected body to have 1 lines, got 2",
+		},
+		{
+			name: "Body size and sha256",
+			expect: `
+body_size: 5
+body_sha256: 2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824`,
+			body: "hello",
+		},
+		{
+			name:    "Body size mismatch",
+			expect:  `body_size: 4`,
+			body:    "hello",
+			wantErr: "text not equal: expected body size to be 4 bytes, got 5",
+		},
+		{
+			name:    "Body sha256 mismatch",
+			expect:  `body_sha256: abc`,
+			body:    "hello",
+			wantErr: "text not equal: expected body sha256 to be abc, got 2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var expect app.TestExpect
+			require.NoError(t, yaml.Unmarshal([]byte(tc.expect), &expect))
+
+			a := &app.Abdd{LastResponse: &app.LastResponse{Body: toPointer(tc.body)}}
+			err := a.ValidateResponse(&app.Test{Expect: expect})
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing is the breakdown of where time was spent making a request. Phases
+// are summed across connections when redirects are followed.
+type Timing struct {
+	DNS       time.Duration
+	Connect   time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
+	Total     time.Duration
+}
+
+func (t Timing) String() string {
+	return fmt.Sprintf("dns=%s connect=%s tls=%s ttfb=%s total=%s", t.DNS, t.Connect, t.TLS, t.FirstByte, t.Total)
+}
+
+type TimingLimits struct {
+	DNS       *time.Duration `yaml:"dns,omitempty"`
+	Connect   *time.Duration `yaml:"connect,omitempty"`
+	TLS       *time.Duration `yaml:"tls,omitempty"`
+	FirstByte *time.Duration `yaml:"ttfb,omitempty"`
+}
+
+// timingRecorder collects the trace callbacks of a request. Dialing can try
+// several addresses at once, so the callbacks may run concurrently.
+type timingRecorder struct {
+	mu       sync.Mutex
+	start    time.Time
+	dnsStart time.Time
+	tlsStart time.Time
+	timing   Timing
+
+	// dialStart is when the first of the dials in progress started and
+	// dialing how many of them have not finished.
+	dialStart time.Time
+	dialing   int
+}
+
+// withTiming attaches an httptrace to the context that records into the returned recorder.
+func withTiming(ctx context.Context) (context.Context, *timingRecorder) {
+	r := &timingRecorder{start: time.Now()}
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { r.record(func() { r.dnsStart = time.Now() }) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { r.record(func() { r.timing.DNS += time.Since(r.dnsStart) }) },
+		ConnectStart:         func(string, string) { r.record(r.connectStart) },
+		ConnectDone:          func(_, _ string, err error) { r.record(func() { r.connectDone(err) }) },
+		TLSHandshakeStart:    func() { r.record(func() { r.tlsStart = time.Now() }) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { r.record(func() { r.timing.TLS += time.Since(r.tlsStart) }) },
+		GotFirstResponseByte: func() { r.record(func() { r.timing.FirstByte = time.Since(r.start) }) },
+	}
+	return httptrace.WithClientTrace(ctx, trace), r
+}
+
+func (r *timingRecorder) record(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn()
+}
+
+func (r *timingRecorder) connectStart() {
+	if r.dialing == 0 {
+		r.dialStart = time.Now()
+	}
+	r.dialing++
+}
+
+// connectDone counts the time from the first dial to the first one that
+// succeeds, so parallel attempts at several addresses are not summed.
+func (r *timingRecorder) connectDone(err error) {
+	r.dialing--
+	if err == nil && !r.dialStart.IsZero() {
+		r.timing.Connect += time.Since(r.dialStart)
+		r.dialStart = time.Time{}
+	}
+	if r.dialing == 0 {
+		r.dialStart = time.Time{}
+	}
+}
+
+// finish records the total duration and returns the collected timing.
+func (r *timingRecorder) finish() Timing {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timing.Total = time.Since(r.start)
+	return r.timing
+}
+
+func (a *Abdd) validateTiming(t *Test) error {
+	timing := a.LastResponse.Timing
+
+	if t.Expect.MaxDuration != nil && timing.Total > *t.Expect.MaxDuration {
+		return fmt.Errorf("%w: expected request to take at most %s, took %s", ErrDurationExceeded, *t.Expect.MaxDuration, timing.Total)
+	}
+
+	if t.Expect.MaxTiming == nil {
+		return nil
+	}
+
+	phases := []struct {
+		name   string
+		limit  *time.Duration
+		actual time.Duration
+	}{
+		{"dns", t.Expect.MaxTiming.DNS, timing.DNS},
+		{"connect", t.Expect.MaxTiming.Connect, timing.Connect},
+		{"tls", t.Expect.MaxTiming.TLS, timing.TLS},
+		{"ttfb", t.Expect.MaxTiming.FirstByte, timing.FirstByte},
+	}
+	for _, phase := range phases {
+		if phase.limit != nil && phase.actual > *phase.limit {
+			return fmt.Errorf("%w: expected %s to take at most %s, took %s", ErrDurationExceeded, phase.name, *phase.limit, phase.actual)
+		}
+	}
+
+	return nil
+}
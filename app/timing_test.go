@@ -0,0 +1,134 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeRequestTiming(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	a := &app.Abdd{
+		Global: app.Global{Config: app.Config{BaseURL: server.URL}},
+		Client: server.Client(),
+	}
+
+	err := a.MakeRequest(&app.Test{Request: &app.TestRequest{Method: "GET", URL: "/"}})
+	require.NoError(t, err)
+
+	timing := a.LastResponse.Timing
+	assert.Greater(t, timing.Connect, time.Duration(0))
+	assert.Greater(t, timing.TLS, time.Duration(0))
+	assert.GreaterOrEqual(t, timing.FirstByte, 10*time.Millisecond)
+	assert.GreaterOrEqual(t, timing.Total, timing.FirstByte)
+}
+
+func TestMakeRequestTimingParallelDials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// Dial twice at once like Happy Eyeballs does for IPv4 and IPv6, so the
+	// connect callbacks of the trace run concurrently.
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conns := make(chan net.Conn, 2)
+		var wg sync.WaitGroup
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var d net.Dialer
+				if conn, err := d.DialContext(ctx, network, addr); err == nil {
+					conns <- conn
+				}
+			}()
+		}
+		wg.Wait()
+		close(conns)
+
+		first := <-conns
+		for conn := range conns {
+			conn.Close()
+		}
+		if first == nil {
+			return nil, fmt.Errorf("dial %s failed", addr)
+		}
+		return first, nil
+	}
+
+	a := &app.Abdd{
+		Global: app.Global{Config: app.Config{BaseURL: server.URL}},
+		Client: &http.Client{Transport: &http.Transport{DialContext: dial}},
+	}
+
+	err := a.MakeRequest(&app.Test{Request: &app.TestRequest{Method: "GET", URL: "/"}})
+	require.NoError(t, err)
+	assert.Greater(t, a.LastResponse.Timing.Connect, time.Duration(0))
+	assert.LessOrEqual(t, a.LastResponse.Timing.Connect, a.LastResponse.Timing.Total)
+}
+
+func TestValidateResponseTiming(t *testing.T) {
+	timing := app.Timing{
+		DNS:       5 * time.Millisecond,
+		Connect:   10 * time.Millisecond,
+		TLS:       20 * time.Millisecond,
+		FirstByte: 200 * time.Millisecond,
+		Total:     250 * time.Millisecond,
+	}
+
+	testCases := []struct {
+		name        string
+		expect      app.TestExpect
+		expectedErr error
+	}{
+		{
+			name:   "Within max duration",
+			expect: app.TestExpect{MaxDuration: toPointer(500 * time.Millisecond)},
+		},
+		{
+			name:        "Exceeds max duration",
+			expect:      app.TestExpect{MaxDuration: toPointer(100 * time.Millisecond)},
+			expectedErr: fmt.Errorf("%w: expected request to take at most %s, took %s", app.ErrDurationExceeded, 100*time.Millisecond, 250*time.Millisecond),
+		},
+		{
+			name: "Within phase limits",
+			expect: app.TestExpect{MaxTiming: &app.TimingLimits{
+				DNS:       toPointer(10 * time.Millisecond),
+				FirstByte: toPointer(300 * time.Millisecond),
+			}},
+		},
+		{
+			name: "Exceeds phase limit",
+			expect: app.TestExpect{MaxTiming: &app.TimingLimits{
+				TLS: toPointer(15 * time.Millisecond),
+			}},
+			expectedErr: fmt.Errorf("%w: expected %s to take at most %s, took %s", app.ErrDurationExceeded, "tls", 15*time.Millisecond, 20*time.Millisecond),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &app.Abdd{LastResponse: &app.LastResponse{Timing: timing}}
+
+			err := a.ValidateResponse(&app.Test{Expect: tc.expect})
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+			}
+		})
+	}
+}
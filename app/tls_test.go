@@ -0,0 +1,183 @@
+package app_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/davesavic/abdd/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)
+	require.NoError(t, err)
+}
+
+func writeClientCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "abdd"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDer)
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	tempDir := t.TempDir()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(tempDir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+	certFile, keyFile := writeClientCertificate(t, tempDir)
+
+	testCases := []struct {
+		name       string
+		config     app.TLSConfig
+		wantErr    string
+		wantReqErr bool
+		wantBody   string
+	}{
+		{
+			name:       "Unknown CA is rejected",
+			config:     app.TLSConfig{},
+			wantReqErr: true,
+		},
+		{
+			name:   "Custom CA",
+			config: app.TLSConfig{CAFile: caFile},
+		},
+		{
+			name:   "Insecure skip verify",
+			config: app.TLSConfig{InsecureSkipVerify: true},
+		},
+		{
+			name:     "Client certificate",
+			config:   app.TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+			wantBody: "abdd",
+		},
+		{
+			name:       "Server name mismatch",
+			config:     app.TLSConfig{CAFile: caFile, ServerName: "abdd.invalid"},
+			wantReqErr: true,
+		},
+		{
+			name:    "Missing CA file",
+			config:  app.TLSConfig{CAFile: filepath.Join(tempDir, "missing.pem")},
+			wantErr: "failed to read ca file",
+		},
+		{
+			name:    "Certificate without key",
+			config:  app.TLSConfig{CertFile: certFile},
+			wantErr: "both cert_file and key_file must be provided",
+		},
+		{
+			name:    "Unsupported min version",
+			config:  app.TLSConfig{MinVersion: "2.0"},
+			wantErr: "unsupported tls min_version 2.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := tc.config.ClientConfig()
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			a := &app.Abdd{
+				Global: app.Global{Config: app.Config{BaseURL: server.URL}},
+				Client: &http.Client{Transport: &http.Transport{TLSClientConfig: config}},
+			}
+			err = a.MakeRequest(&app.Test{Request: &app.TestRequest{Method: "GET", URL: "/"}})
+			if tc.wantReqErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, *a.LastResponse.Body)
+		})
+	}
+}
+
+func TestTLSConfigMinVersion(t *testing.T) {
+	config, err := (&app.TLSConfig{MinVersion: "1.3"}).ClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+}
+
+func TestNewResolvesTLSPathsFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	certDir := filepath.Join(dir, "certs")
+	require.NoError(t, os.Mkdir(certDir, 0o755))
+	testFolder := filepath.Join(dir, "tests")
+	require.NoError(t, os.Mkdir(testFolder, 0o755))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	writePEM(t, filepath.Join(certDir, "ca.pem"), "CERTIFICATE", server.Certificate().Raw)
+	writeClientCertificate(t, certDir)
+
+	configFile := filepath.Join(dir, "abdd.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+global:
+  config:
+    base_url: `+server.URL+`
+    tls:
+      ca_file: certs/ca.pem
+      cert_file: certs/client.pem
+      key_file: certs/client-key.pem
+`), 0o644))
+
+	// The paths are relative to the config file, not the working directory.
+	a, err := app.New(app.AbddArgs{ConfigFile: configFile, Folders: []string{testFolder}})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(certDir, "ca.pem"), a.Global.Config.TLS.CAFile)
+
+	err = a.MakeRequest(&app.Test{Request: &app.TestRequest{Method: "GET", URL: "/"}})
+	require.NoError(t, err)
+	assert.Equal(t, "abdd", *a.LastResponse.Body)
+}
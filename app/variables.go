@@ -14,10 +14,21 @@ func (a *Abdd) ReplaceVariables(t *Test) error {
 	}
 
 	if t.Command != nil {
-		if t.Command.Directory != "" {
-			t.Command.Directory = a.replaceVariablesInText(t.Command.Directory)
+		command := *t.Command
+		if command.Directory != "" {
+			command.Directory = a.replaceVariablesInText(command.Directory)
 		}
-		t.Command.Command = a.replaceVariablesInText(t.Command.Command)
+		command.Command = a.replaceVariablesInText(command.Command)
+		command.Stdin = a.replaceVariablesInText(command.Stdin)
+
+		if command.Env != nil {
+			env := make(map[string]string, len(command.Env))
+			for key, value := range command.Env {
+				env[key] = a.replaceVariablesInText(value)
+			}
+			command.Env = env
+		}
+		t.Command = &command
 	}
 
 	if t.Request != nil {
@@ -32,41 +43,121 @@ func (a *Abdd) ReplaceVariables(t *Test) error {
 			body = &bodyValue
 		}
 
-		t.Request = &TestRequest{
-			Method:  t.Request.Method,
-			URL:     a.replaceVariablesInText(t.Request.URL),
-			Body:    body,
-			Headers: headers,
-		}
+		request := *t.Request
+		request.URL = a.replaceVariablesInText(t.Request.URL)
+		request.Body = body
+		request.Headers = headers
+		request.Session = a.replaceVariablesInText(t.Request.Session)
+		t.Request = &request
 	}
 
 	if t.Expect.Headers != nil {
-		headers := map[string]string{}
+		headers := map[string]HeaderExpect{}
 		for key, value := range t.Expect.Headers {
-			headers[key] = a.replaceVariablesInText(value)
+			headers[key] = a.replaceVariablesInHeaderExpect(value)
 		}
 		t.Expect.Headers = headers
 	}
 
-	if t.Expect.Json != nil {
-		json := map[string]any{}
-		for key, value := range t.Expect.Json {
-			json[key] = a.replaceVariablesInText(fmt.Sprintf("%v", value))
+	if t.Expect.Redirects != nil {
+		redirects := make([]ExpectRedirect, len(t.Expect.Redirects))
+		for i, redirect := range t.Expect.Redirects {
+			redirect.URL = a.replaceVariablesInText(redirect.URL)
+			redirect.Location = a.replaceVariablesInText(redirect.Location)
+			redirects[i] = redirect
 		}
-		t.Expect.Json = json
+		t.Expect.Redirects = redirects
+	}
+
+	if t.Expect.Body != nil {
+		body := *t.Expect.Body
+		body.Equals = a.replaceVariablesInValue(body.Equals)
+		t.Expect.Body = &body
+	}
+
+	if t.Expect.Stdout != nil {
+		t.Expect.Stdout = a.replaceVariablesInTextExpect(t.Expect.Stdout)
+	}
+
+	if t.Expect.Stderr != nil {
+		t.Expect.Stderr = a.replaceVariablesInTextExpect(t.Expect.Stderr)
+	}
+
+	if t.Expect.Text != nil {
+		t.Expect.Text = a.replaceVariablesInTextExpect(t.Expect.Text)
+	}
+	t.Expect.BodySHA256 = a.replaceVariablesInText(t.Expect.BodySHA256)
+
+	if t.Expect.XML != nil {
+		t.Expect.XML = a.replaceVariablesInValue(t.Expect.XML).(map[string]any)
+	}
+
+	if t.Expect.HTML != nil {
+		t.Expect.HTML = a.replaceVariablesInValue(t.Expect.HTML).(map[string]any)
+	}
+
+	t.Expect.JWT = a.replaceVariablesInJWTExpect(t.Expect.JWT)
+	t.Expect.Each = a.replaceVariablesInQuantifier(t.Expect.Each)
+	t.Expect.Any = a.replaceVariablesInQuantifier(t.Expect.Any)
+
+	if t.Expect.Json != nil {
+		t.Expect.Json = a.replaceVariablesInValue(t.Expect.Json).(map[string]any)
 	}
 
 	return nil
 }
 
+var placeholderPattern = regexp.MustCompile(`^\${([^}]+)}$`)
+
+// storeText formats a stored value for interpolation into text; structured
+// values are written as JSON.
+func storeText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return "null"
+	default:
+		return jsonString(v)
+	}
+}
+
 func (a *Abdd) replaceVariablesInText(text string) string {
 	r := regexp.MustCompile(`\${([^}]+)}`)
 	return r.ReplaceAllStringFunc(text, func(match string) string {
 		// Extract key name without ${ and }
 		key := match[2 : len(match)-1]
-		if val, ok := a.Store[key]; ok {
-			return fmt.Sprintf("%v", val)
+		if val, ok := a.lookupVariable(key); ok {
+			return storeText(val)
 		}
 		return match
 	})
 }
+
+// replaceVariablesInValue replaces variables in every string of a decoded YAML value.
+// A string consisting of a single placeholder takes the stored value with its type.
+func (a *Abdd) replaceVariablesInValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		if m := placeholderPattern.FindStringSubmatch(v); m != nil {
+			if val, ok := a.lookupVariable(m[1]); ok {
+				return val
+			}
+		}
+		return a.replaceVariablesInText(v)
+	case map[string]any:
+		replaced := make(map[string]any, len(v))
+		for key, child := range v {
+			replaced[key] = a.replaceVariablesInValue(child)
+		}
+		return replaced
+	case []any:
+		replaced := make([]any, len(v))
+		for i, child := range v {
+			replaced[i] = a.replaceVariablesInValue(child)
+		}
+		return replaced
+	default:
+		return value
+	}
+}
@@ -1,6 +1,7 @@
 package app_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/davesavic/abdd/app"
@@ -79,14 +80,14 @@ func TestReplaceVariables(t *testing.T) {
 			name: "Replace variables in expect headers",
 			setup: func(a *app.Abdd, test *app.Test) {
 				a.Store["token"] = "1234567890"
-				test.Expect.Headers = map[string]string{
-					"Authorization": "Bearer ${token}",
+				test.Expect.Headers = map[string]app.HeaderExpect{
+					"Authorization": {Equals: "Bearer ${token}"},
 				}
 				test.Request = &app.TestRequest{}
 			},
 			expects: func(a app.Abdd, test *app.Test, err error) {
 				assert.NoError(t, err)
-				assert.Equal(t, "Bearer 1234567890", test.Expect.Headers["Authorization"])
+				assert.Equal(t, "Bearer 1234567890", test.Expect.Headers["Authorization"].Equals)
 			},
 		},
 		{
@@ -103,6 +104,46 @@ func TestReplaceVariables(t *testing.T) {
 				assert.Equal(t, "chimmy@gmail.com", test.Expect.Json["extractedKey"])
 			},
 		},
+		{
+			name: "Whole placeholders keep the stored type",
+			setup: func(a *app.Abdd, test *app.Test) {
+				a.Store["businessId"] = json.Number("42")
+				a.Store["active"] = true
+				test.Expect.Json = map[string]any{
+					"id":     "${businessId}",
+					"active": "${active}",
+					"label":  "business ${businessId}",
+					"empty":  nil,
+				}
+				test.Request = &app.TestRequest{}
+			},
+			expects: func(a app.Abdd, test *app.Test, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, map[string]any{
+					"id":     json.Number("42"),
+					"active": true,
+					"label":  "business 42",
+					"empty":  nil,
+				}, test.Expect.Json)
+			},
+		},
+		{
+			name: "Replace variables in expect body",
+			setup: func(a *app.Abdd, test *app.Test) {
+				a.Store["customerEmail"] = "frodo@gmail.com"
+				test.Expect.Body = &app.BodyExpect{
+					Equals: map[string]any{
+						"emails": []any{"${customerEmail}"},
+						"count":  1,
+					},
+				}
+				test.Request = &app.TestRequest{}
+			},
+			expects: func(a app.Abdd, test *app.Test, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, map[string]any{"emails": []any{"frodo@gmail.com"}, "count": 1}, test.Expect.Body.Equals)
+			},
+		},
 		{
 			name:  "Test with no request or command",
 			setup: func(a *app.Abdd, test *app.Test) {},
@@ -4,6 +4,10 @@ Copyright © 2025 Dave Savic
 package cmd
 
 import (
+	"errors"
+	"os"
+	"syscall"
+
 	"github.com/davesavic/abdd/app"
 	"github.com/spf13/cobra"
 )
@@ -21,9 +25,11 @@ var runCmd = &cobra.Command{
 		}
 
 		a, err := app.New(app.AbddArgs{
-			ConfigFile: cmd.Flag("config").Value.String(),
-			Verbose:    cmd.Flag("verbose").Value.String() == "true",
-			Folders:    folders,
+			ConfigFile:      cmd.Flag("config").Value.String(),
+			Verbose:         cmd.Flag("verbose").Value.String() == "true",
+			Folders:         folders,
+			CoverageReport:  cmd.Flag("coverage-report").Value.String(),
+			UpdateSnapshots: cmd.Flag("update-snapshots").Value.String() == "true",
 		})
 		if err != nil {
 			cmd.PrintErrf("Error: %v\n", err)
@@ -33,6 +39,16 @@ var runCmd = &cobra.Command{
 		err = a.Run()
 		if err != nil {
 			cmd.PrintErrf("Error: %v\n", err)
+
+			// Exit the way a shell reports a process ended by the signal.
+			var interrupted *app.InterruptedError
+			if errors.As(err, &interrupted) {
+				code := 130
+				if sig, ok := interrupted.Signal.(syscall.Signal); ok {
+					code = 128 + int(sig)
+				}
+				os.Exit(code)
+			}
 			return
 		}
 	},
@@ -43,6 +59,8 @@ func init() {
 
 	runCmd.Flags().StringSliceP("folders", "f", []string{}, "Folders to run tests from")
 	runCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
+	runCmd.Flags().String("coverage-report", "", "Write the OpenAPI coverage report as JSON to this file")
+	runCmd.Flags().BoolP("update-snapshots", "u", false, "Write response snapshots instead of comparing them")
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command